@@ -0,0 +1,78 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package availabilityzone
+
+import (
+	"context"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	topologyv1a1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+// DefaultCapacityRefreshInterval is how often each AvailabilityZone's capacity
+// status is recomputed, absent a more specific configuration.
+const DefaultCapacityRefreshInterval = 5 * time.Minute
+
+// Reconciler periodically refreshes AvailabilityZone.Status with capacity and
+// utilization aggregated from vSphere.
+type Reconciler struct {
+	client.Client
+	VMProvider      vmprovider.VirtualMachineProviderInterface
+	RefreshInterval time.Duration
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&topologyv1a1.AvailabilityZone{}).
+		Complete(r)
+}
+
+// Reconcile recomputes az.Status and requeues itself after RefreshInterval so
+// capacity stays current even when nothing about the AvailabilityZone changed.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	az := &topologyv1a1.AvailabilityZone{}
+	if err := r.Get(ctx, req.NamespacedName, az); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	capacity, err := r.VMProvider.GetAvailabilityZoneCapacity(ctx, az)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	az.Status.TotalCPU = capacity.TotalCPU
+	az.Status.TotalMemory = capacity.TotalMemory
+	az.Status.UsedCPU = capacity.UsedCPU
+	az.Status.UsedMemory = capacity.UsedMemory
+	az.Status.VMCount = capacity.VMCount
+
+	clusterStatus := make([]topologyv1a1.ClusterStatus, 0, len(capacity.Clusters))
+	for _, c := range capacity.Clusters {
+		clusterStatus = append(clusterStatus, topologyv1a1.ClusterStatus{
+			ClusterComputeResourceMoId: c.ClusterComputeResourceMoId,
+			TotalCPU:                   c.TotalCPU,
+			TotalMemory:                c.TotalMemory,
+			UsedCPU:                    c.UsedCPU,
+			UsedMemory:                 c.UsedMemory,
+			VMCount:                    c.VMCount,
+		})
+	}
+	az.Status.ClusterStatus = clusterStatus
+
+	if err := r.Status().Update(ctx, az); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	interval := r.RefreshInterval
+	if interval <= 0 {
+		interval = DefaultCapacityRefreshInterval
+	}
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinesnapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
+
+	snapshotv1a1 "github.com/vmware-tanzu/vm-operator/external/vm-operator-api/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	// MaxRetentionDefault bounds how many snapshots a VM may keep when its
+	// VirtualMachineSnapshot spec does not set RetentionLimit.
+	MaxRetentionDefault = 10
+
+	snapshotFinalizer = "virtualmachinesnapshot.vmoperator.vmware.com/finalizer"
+
+	// virtualMachineNameField is the field index enforceRetention queries to
+	// find all VirtualMachineSnapshots belonging to a given VM.
+	virtualMachineNameField = "spec.virtualMachineName"
+)
+
+// Reconciler reconciles a VirtualMachineSnapshot object.
+type Reconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	VMProvider vmprovider.VirtualMachineProviderInterface
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &snapshotv1a1.VirtualMachineSnapshot{},
+		virtualMachineNameField, func(obj client.Object) []string {
+			snap := obj.(*snapshotv1a1.VirtualMachineSnapshot)
+			return []string{snap.Spec.VirtualMachineName}
+		}); err != nil {
+		return fmt.Errorf("indexing %s: %w", virtualMachineNameField, err)
+	}
+
+	if r.Scheme == nil {
+		r.Scheme = mgr.GetScheme()
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&snapshotv1a1.VirtualMachineSnapshot{}).
+		Complete(r)
+}
+
+// Reconcile creates, tracks, or tears down a vSphere snapshot for the VirtualMachine
+// named in snap.Spec.VirtualMachineName, enforcing the parent/child lineage recorded
+// via owner references and the configured retention limit.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	snap := &snapshotv1a1.VirtualMachineSnapshot{}
+	if err := r.Get(ctx, req.NamespacedName, snap); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	vm := &v1alpha1.VirtualMachine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: snap.Namespace, Name: snap.Spec.VirtualMachineName}, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			markSnapshotFailed(snap, "VirtualMachineNotFound", fmt.Sprintf("VirtualMachine %q not found", snap.Spec.VirtualMachineName))
+			return ctrl.Result{}, r.Status().Update(ctx, snap)
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !snap.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, vm, snap)
+	}
+
+	needsUpdate := false
+	if !controllerutil.ContainsFinalizer(snap, snapshotFinalizer) {
+		controllerutil.AddFinalizer(snap, snapshotFinalizer)
+		needsUpdate = true
+	}
+
+	if snap.Spec.ParentSnapshotName != "" && !hasOwnerReference(snap, snap.Spec.ParentSnapshotName) {
+		parent := &snapshotv1a1.VirtualMachineSnapshot{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: snap.Namespace, Name: snap.Spec.ParentSnapshotName}, parent); err != nil {
+			if apierrors.IsNotFound(err) {
+				markSnapshotFailed(snap, "ParentSnapshotNotFound", fmt.Sprintf("parent VirtualMachineSnapshot %q not found", snap.Spec.ParentSnapshotName))
+				return ctrl.Result{}, r.Status().Update(ctx, snap)
+			}
+			return ctrl.Result{}, err
+		}
+		if err := controllerutil.SetOwnerReference(parent, snap, r.Scheme); err != nil {
+			return ctrl.Result{}, fmt.Errorf("setting owner reference to parent snapshot %q: %w", parent.Name, err)
+		}
+		needsUpdate = true
+	}
+
+	if needsUpdate {
+		if err := r.Update(ctx, snap); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if snap.Status.SnapshotID != "" {
+		// Already created: nothing left to reconcile for the happy path.
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.enforceRetention(ctx, vm, snap); err != nil {
+		return ctrl.Result{}, fmt.Errorf("enforcing retention limit for VM %q: %w", vm.Name, err)
+	}
+
+	markSnapshotInProgress(snap)
+	if err := r.Status().Update(ctx, snap); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.VMProvider.CreateVirtualMachineSnapshot(ctx, vm, snap); err != nil {
+		markSnapshotFailed(snap, snapshotFaultReason(err), err.Error())
+		_ = r.Status().Update(ctx, snap)
+		return ctrl.Result{}, err
+	}
+
+	markSnapshotReady(snap)
+	return ctrl.Result{}, r.Status().Update(ctx, snap)
+}
+
+// reconcileDelete gates removal of snap until any child snapshots are gone,
+// unless snap's own Spec.DeletionPolicy is Cascade.
+func (r *Reconciler) reconcileDelete(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(snap, snapshotFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if snap.Spec.DeletionPolicy != snapshotv1a1.VirtualMachineSnapshotDeletionPolicyCascade {
+		children, err := r.listChildren(ctx, snap)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if len(children) > 0 {
+			markSnapshotFailed(snap, "DependentsExist", fmt.Sprintf("%d dependent snapshot(s) must be removed first, or set deletionPolicy: Cascade", len(children)))
+			return ctrl.Result{}, r.Status().Update(ctx, snap)
+		}
+	}
+
+	if err := r.VMProvider.DeleteVirtualMachineSnapshot(ctx, vm, snap); err != nil {
+		return ctrl.Result{}, fmt.Errorf("deleting vSphere snapshot for %q: %w", snap.Name, err)
+	}
+
+	controllerutil.RemoveFinalizer(snap, snapshotFinalizer)
+	return ctrl.Result{}, r.Update(ctx, snap)
+}
+
+// hasOwnerReference reports whether snap already carries an owner reference
+// to the VirtualMachineSnapshot named parentName.
+func hasOwnerReference(snap *snapshotv1a1.VirtualMachineSnapshot, parentName string) bool {
+	for _, ref := range snap.OwnerReferences {
+		if ref.Kind == "VirtualMachineSnapshot" && ref.Name == parentName {
+			return true
+		}
+	}
+	return false
+}
+
+// listChildren returns the VirtualMachineSnapshots whose parent lineage owner
+// reference points at snap.
+func (r *Reconciler) listChildren(ctx context.Context, snap *snapshotv1a1.VirtualMachineSnapshot) ([]snapshotv1a1.VirtualMachineSnapshot, error) {
+	list := &snapshotv1a1.VirtualMachineSnapshotList{}
+	if err := r.List(ctx, list, client.InNamespace(snap.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var children []snapshotv1a1.VirtualMachineSnapshot
+	for _, s := range list.Items {
+		for _, ref := range s.OwnerReferences {
+			if ref.Kind == "VirtualMachineSnapshot" && ref.Name == snap.Name {
+				children = append(children, s)
+			}
+		}
+	}
+	return children, nil
+}
+
+// enforceRetention deletes the oldest snapshots for vm once its snapshots other
+// than snap have reached snap's RetentionLimit (or MaxRetentionDefault, if
+// unset), making room for snap itself.
+func (r *Reconciler) enforceRetention(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error {
+	list := &snapshotv1a1.VirtualMachineSnapshotList{}
+	if err := r.List(ctx, list, client.InNamespace(vm.Namespace),
+		client.MatchingFields{virtualMachineNameField: vm.Name}); err != nil {
+		return err
+	}
+
+	limit := MaxRetentionDefault
+	if snap.Spec.RetentionLimit > 0 {
+		limit = int(snap.Spec.RetentionLimit)
+	}
+	existing := removeByName(list.Items, snap.Name)
+	for len(existing) >= limit {
+		oldest := oldestSnapshot(existing)
+		if err := r.Delete(ctx, &oldest); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		existing = removeByName(existing, oldest.Name)
+	}
+
+	return nil
+}
+
+func oldestSnapshot(snaps []snapshotv1a1.VirtualMachineSnapshot) snapshotv1a1.VirtualMachineSnapshot {
+	oldest := snaps[0]
+	for _, s := range snaps[1:] {
+		if s.CreationTimestamp.Before(&oldest.CreationTimestamp) {
+			oldest = s
+		}
+	}
+	return oldest
+}
+
+func removeByName(snaps []snapshotv1a1.VirtualMachineSnapshot, name string) []snapshotv1a1.VirtualMachineSnapshot {
+	out := make([]snapshotv1a1.VirtualMachineSnapshot, 0, len(snaps))
+	for _, s := range snaps {
+		if s.Name != name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// snapshotFaultReason extracts the vmprovider.SnapshotFaultReason behind err,
+// if any, so it can be recorded on the Failed condition. Errors that didn't
+// originate as a vmprovider.SnapshotFaultError (e.g. a k8s API error) report
+// SnapshotFaultReasonGeneric.
+func snapshotFaultReason(err error) string {
+	var faultErr *vmprovider.SnapshotFaultError
+	if errors.As(err, &faultErr) {
+		return string(faultErr.Reason)
+	}
+	return string(vmprovider.SnapshotFaultReasonGeneric)
+}
+
+func markSnapshotInProgress(snap *snapshotv1a1.VirtualMachineSnapshot) {
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionInProgress, "True", "", "")
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionReady, "False", "", "")
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionFailed, "False", "", "")
+}
+
+func markSnapshotReady(snap *snapshotv1a1.VirtualMachineSnapshot) {
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionInProgress, "False", "", "")
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionReady, "True", "", "")
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionFailed, "False", "", "")
+}
+
+func markSnapshotFailed(snap *snapshotv1a1.VirtualMachineSnapshot, reason, message string) {
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionInProgress, "False", "", "")
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionReady, "False", "", "")
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionFailed, "True", reason, message)
+}
+
+func setCondition(snap *snapshotv1a1.VirtualMachineSnapshot, condType snapshotv1a1.VirtualMachineSnapshotConditionType, status, reason, message string) {
+	for i := range snap.Status.Conditions {
+		if snap.Status.Conditions[i].Type == condType {
+			snap.Status.Conditions[i].Status = status
+			snap.Status.Conditions[i].Reason = reason
+			snap.Status.Conditions[i].Message = message
+			return
+		}
+	}
+	snap.Status.Conditions = append(snap.Status.Conditions, snapshotv1a1.VirtualMachineSnapshotCondition{
+		Type:    condType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinesnapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
+
+	snapshotv1a1 "github.com/vmware-tanzu/vm-operator/external/vm-operator-api/api/v1alpha1"
+	fakeprovider "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/fake"
+)
+
+func snapshotAt(name string, t time.Time) snapshotv1a1.VirtualMachineSnapshot {
+	return snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(t),
+		},
+	}
+}
+
+func TestOldestSnapshot(t *testing.T) {
+	now := time.Now()
+	snaps := []snapshotv1a1.VirtualMachineSnapshot{
+		snapshotAt("newest", now),
+		snapshotAt("oldest", now.Add(-time.Hour)),
+		snapshotAt("middle", now.Add(-time.Minute)),
+	}
+
+	oldest := oldestSnapshot(snaps)
+	if oldest.Name != "oldest" {
+		t.Errorf("oldestSnapshot() = %q, want %q", oldest.Name, "oldest")
+	}
+}
+
+func TestRemoveByName(t *testing.T) {
+	now := time.Now()
+	snaps := []snapshotv1a1.VirtualMachineSnapshot{
+		snapshotAt("a", now),
+		snapshotAt("b", now),
+		snapshotAt("c", now),
+	}
+
+	out := removeByName(snaps, "b")
+	if len(out) != 2 {
+		t.Fatalf("removeByName() left %d snapshots, want 2", len(out))
+	}
+	for _, s := range out {
+		if s.Name == "b" {
+			t.Errorf("removeByName() did not remove %q", "b")
+		}
+	}
+}
+
+func TestSetConditionUpdatesInPlace(t *testing.T) {
+	snap := &snapshotv1a1.VirtualMachineSnapshot{}
+
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionReady, "False", "", "not yet")
+	setCondition(snap, snapshotv1a1.VirtualMachineSnapshotConditionReady, "True", "", "")
+
+	if len(snap.Status.Conditions) != 1 {
+		t.Fatalf("len(Conditions) = %d, want 1", len(snap.Status.Conditions))
+	}
+	if got := snap.Status.Conditions[0].Status; got != "True" {
+		t.Errorf("Conditions[0].Status = %q, want %q", got, "True")
+	}
+}
+
+func TestMarkSnapshotReadyClearsFailedAndInProgress(t *testing.T) {
+	snap := &snapshotv1a1.VirtualMachineSnapshot{}
+
+	markSnapshotInProgress(snap)
+	markSnapshotFailed(snap, "Generic", "boom")
+	markSnapshotReady(snap)
+
+	for _, c := range snap.Status.Conditions {
+		switch c.Type {
+		case snapshotv1a1.VirtualMachineSnapshotConditionReady:
+			if c.Status != "True" {
+				t.Errorf("Ready condition status = %q, want True", c.Status)
+			}
+		case snapshotv1a1.VirtualMachineSnapshotConditionInProgress, snapshotv1a1.VirtualMachineSnapshotConditionFailed:
+			if c.Status != "False" {
+				t.Errorf("%s condition status = %q, want False", c.Type, c.Status)
+			}
+		}
+	}
+}
+
+// newTestReconciler builds a Reconciler backed by a fake client seeded with
+// objs, and a fake VMProvider with CreateVirtualMachineSnapshotFn stubbed to
+// succeed and assign snapshotID as the resulting moref.
+func newTestReconciler(t *testing.T, snapshotID string, objs ...client.Object) (*Reconciler, *fakeprovider.VMProvider) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("registering v1alpha1 scheme: %v", err)
+	}
+	if err := snapshotv1a1.AddToScheme(scheme); err != nil {
+		t.Fatalf("registering snapshotv1a1 scheme: %v", err)
+	}
+
+	provider := &fakeprovider.VMProvider{
+		CreateVirtualMachineSnapshotFn: func(_ context.Context, _ *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error {
+			snap.Status.SnapshotID = snapshotID
+			return nil
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&snapshotv1a1.VirtualMachineSnapshot{}).
+		WithIndex(&snapshotv1a1.VirtualMachineSnapshot{}, virtualMachineNameField, func(obj client.Object) []string {
+			return []string{obj.(*snapshotv1a1.VirtualMachineSnapshot).Spec.VirtualMachineName}
+		}).
+		WithObjects(objs...).
+		Build()
+
+	return &Reconciler{Client: c, Scheme: scheme, VMProvider: provider}, provider
+}
+
+func TestReconcileCreatesSnapshotAndSetsOwnerReference(t *testing.T) {
+	vm := &v1alpha1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vm-1"}}
+	parent := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "parent"},
+		Spec:       snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+	child := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "child"},
+		Spec:       snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1", ParentSnapshotName: "parent"},
+	}
+
+	r, _ := newTestReconciler(t, "snap-123", vm, parent, child)
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(child)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	got := &snapshotv1a1.VirtualMachineSnapshot{}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(child), got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got.Status.SnapshotID != "snap-123" {
+		t.Errorf("Status.SnapshotID = %q, want %q", got.Status.SnapshotID, "snap-123")
+	}
+	if !controllerutil.ContainsFinalizer(got, snapshotFinalizer) {
+		t.Errorf("finalizer not set")
+	}
+	if !hasOwnerReference(got, "parent") {
+		t.Errorf("owner reference to parent snapshot not set")
+	}
+}
+
+func TestReconcileDeleteGatedByChildren(t *testing.T) {
+	vm := &v1alpha1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vm-1"}}
+	parent := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              "parent",
+			Finalizers:        []string{snapshotFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+	child := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "child",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "VirtualMachineSnapshot", Name: "parent", APIVersion: snapshotv1a1.GroupVersion.String(), UID: "fake"},
+			},
+		},
+		Spec: snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+
+	r, provider := newTestReconciler(t, "", vm, parent, child)
+	provider.DeleteVirtualMachineSnapshotFn = func(_ context.Context, _ *v1alpha1.VirtualMachine, _ *snapshotv1a1.VirtualMachineSnapshot) error {
+		t.Fatalf("DeleteVirtualMachineSnapshot should not be called while children exist")
+		return nil
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(parent)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	got := &snapshotv1a1.VirtualMachineSnapshot{}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(parent), got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(got, snapshotFinalizer) {
+		t.Errorf("finalizer removed despite a dependent snapshot existing")
+	}
+
+	var failed bool
+	for _, c := range got.Status.Conditions {
+		if c.Type == snapshotv1a1.VirtualMachineSnapshotConditionFailed && c.Status == "True" && c.Reason == "DependentsExist" {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Errorf("Failed condition with reason %q not set", "DependentsExist")
+	}
+}
+
+func TestReconcileDeleteProceedsOnceChildrenGone(t *testing.T) {
+	vm := &v1alpha1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vm-1"}}
+	snap := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              "solo",
+			Finalizers:        []string{snapshotFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+
+	r, provider := newTestReconciler(t, "", vm, snap)
+
+	var deleteCalled bool
+	provider.DeleteVirtualMachineSnapshotFn = func(_ context.Context, _ *v1alpha1.VirtualMachine, _ *snapshotv1a1.VirtualMachineSnapshot) error {
+		deleteCalled = true
+		return nil
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(snap)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if !deleteCalled {
+		t.Errorf("DeleteVirtualMachineSnapshot was not called")
+	}
+
+	got := &snapshotv1a1.VirtualMachineSnapshot{}
+	err := r.Get(context.Background(), client.ObjectKeyFromObject(snap), got)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Get() error = %v, want NotFound once the finalizer is removed", err)
+	}
+}
+
+func TestListChildren(t *testing.T) {
+	vm := &v1alpha1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vm-1"}}
+	parent := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "parent"},
+		Spec:       snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+	child := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "ns",
+			Name:            "child",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "VirtualMachineSnapshot", Name: "parent", APIVersion: snapshotv1a1.GroupVersion.String(), UID: "fake"}},
+		},
+		Spec: snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+	unrelated := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unrelated"},
+		Spec:       snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+
+	r, _ := newTestReconciler(t, "", vm, parent, child, unrelated)
+
+	children, err := r.listChildren(context.Background(), parent)
+	if err != nil {
+		t.Fatalf("listChildren() error = %v", err)
+	}
+	if len(children) != 1 || children[0].Name != "child" {
+		t.Errorf("listChildren() = %v, want [child]", children)
+	}
+}
+
+func TestEnforceRetentionDeletesOldest(t *testing.T) {
+	now := time.Now()
+	vm := &v1alpha1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vm-1"}}
+	older := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "older", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+		Spec:       snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+	middle := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "middle", CreationTimestamp: metav1.NewTime(now.Add(-time.Minute))},
+		Spec:       snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1"},
+	}
+	newer := &snapshotv1a1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "newer", CreationTimestamp: metav1.NewTime(now)},
+		Spec:       snapshotv1a1.VirtualMachineSnapshotSpec{VirtualMachineName: "vm-1", RetentionLimit: 2},
+	}
+
+	r, _ := newTestReconciler(t, "", vm, older, middle, newer)
+
+	if err := r.enforceRetention(context.Background(), vm, newer); err != nil {
+		t.Fatalf("enforceRetention() error = %v", err)
+	}
+
+	err := r.Get(context.Background(), client.ObjectKeyFromObject(older), &snapshotv1a1.VirtualMachineSnapshot{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("older snapshot not deleted by enforceRetention(), Get() error = %v", err)
+	}
+}
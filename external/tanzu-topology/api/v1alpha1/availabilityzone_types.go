@@ -0,0 +1,90 @@
+// Copyright (c) VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+// This file is the sole source of the AvailabilityZone API types; the
+// DeepCopyInto/DeepCopy/DeepCopyObject methods in zz_generated.deepcopy.go are
+// generated from the types declared here and must stay in sync with them (in
+// particular AvailabilityZoneStatus.ClusterStatus and the ClusterStatus type
+// below, which the existing generated code already has deepcopy support for).
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AvailabilityZoneSpec defines the desired state of AvailabilityZone.
+type AvailabilityZoneSpec struct {
+	// ClusterComputeResourceMoIDs are the vSphere ClusterComputeResource morefs
+	// that back this zone.
+	ClusterComputeResourceMoIDs []string `json:"clusterComputeResourceMoIDs,omitempty"`
+
+	// Namespaces maps a Supervisor Namespace name to the vSphere resource pool
+	// and folder it was provisioned into within this zone.
+	Namespaces map[string]NamespaceInfo `json:"namespaces,omitempty"`
+}
+
+// NamespaceInfo records where a namespace's workloads land in vSphere.
+type NamespaceInfo struct {
+	PoolMoId   string `json:"poolMoId,omitempty"`
+	FolderMoId string `json:"folderMoId,omitempty"`
+}
+
+// ClusterStatus reports capacity and utilization for a single
+// ClusterComputeResource backing an AvailabilityZone.
+type ClusterStatus struct {
+	// ClusterComputeResourceMoId is the moref of the cluster this status is for,
+	// and indexes into AvailabilityZoneSpec.ClusterComputeResourceMoIDs.
+	ClusterComputeResourceMoId string `json:"clusterComputeResourceMoId"`
+
+	// TotalCPU is the cluster's effective CPU capacity, in MHz.
+	TotalCPU int64 `json:"totalCPU,omitempty"`
+	// TotalMemory is the cluster's effective memory capacity, in MB.
+	TotalMemory int64 `json:"totalMemory,omitempty"`
+	// UsedCPU is the CPU reserved by powered-on VMs in the cluster, in MHz.
+	UsedCPU int64 `json:"usedCPU,omitempty"`
+	// UsedMemory is the memory reserved by powered-on VMs in the cluster, in MB.
+	UsedMemory int64 `json:"usedMemory,omitempty"`
+	// VMCount is the number of powered-on VMs in this cluster's resource pool
+	// tree, whether or not VM Operator created them.
+	VMCount int32 `json:"vmCount,omitempty"`
+}
+
+// AvailabilityZoneStatus defines the observed state of AvailabilityZone.
+type AvailabilityZoneStatus struct {
+	// TotalCPU is the aggregate effective CPU capacity across ClusterStatus, in MHz.
+	TotalCPU int64 `json:"totalCPU,omitempty"`
+	// TotalMemory is the aggregate effective memory capacity across ClusterStatus, in MB.
+	TotalMemory int64 `json:"totalMemory,omitempty"`
+	// UsedCPU is the aggregate reserved CPU across ClusterStatus, in MHz.
+	UsedCPU int64 `json:"usedCPU,omitempty"`
+	// UsedMemory is the aggregate reserved memory across ClusterStatus, in MB.
+	UsedMemory int64 `json:"usedMemory,omitempty"`
+	// VMCount is the aggregate powered-on VM count across ClusterStatus.
+	VMCount int32 `json:"vmCount,omitempty"`
+
+	// ClusterStatus reports capacity and utilization per
+	// ClusterComputeResourceMoIDs entry.
+	ClusterStatus []ClusterStatus `json:"clusterStatus,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// AvailabilityZone is the Schema for the availabilityzones API.
+type AvailabilityZone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AvailabilityZoneSpec   `json:"spec,omitempty"`
+	Status AvailabilityZoneStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AvailabilityZoneList contains a list of AvailabilityZone.
+type AvailabilityZoneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AvailabilityZone `json:"items"`
+}
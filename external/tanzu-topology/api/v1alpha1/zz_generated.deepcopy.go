@@ -100,6 +100,11 @@ func (in *AvailabilityZoneSpec) DeepCopy() *AvailabilityZoneSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AvailabilityZoneStatus) DeepCopyInto(out *AvailabilityZoneStatus) {
 	*out = *in
+	if in.ClusterStatus != nil {
+		in, out := &in.ClusterStatus, &out.ClusterStatus
+		*out = make([]ClusterStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AvailabilityZoneStatus.
@@ -112,6 +117,21 @@ func (in *AvailabilityZoneStatus) DeepCopy() *AvailabilityZoneStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
+func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespaceInfo) DeepCopyInto(out *NamespaceInfo) {
 	*out = *in
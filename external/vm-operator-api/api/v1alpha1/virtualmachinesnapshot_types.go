@@ -0,0 +1,119 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VirtualMachineSnapshotDeletionPolicy controls what happens to a
+// VirtualMachineSnapshot's dependent (child) snapshots when it is deleted.
+type VirtualMachineSnapshotDeletionPolicy string
+
+const (
+	// VirtualMachineSnapshotDeletionPolicyCascade deletes any dependent
+	// snapshots along with this one.
+	VirtualMachineSnapshotDeletionPolicyCascade VirtualMachineSnapshotDeletionPolicy = "Cascade"
+
+	// VirtualMachineSnapshotDeletionPolicyRetain refuses to delete this
+	// snapshot until its dependent snapshots have been removed.
+	VirtualMachineSnapshotDeletionPolicyRetain VirtualMachineSnapshotDeletionPolicy = "Retain"
+)
+
+// VirtualMachineSnapshotConditionType is the type of a VirtualMachineSnapshot
+// status condition.
+type VirtualMachineSnapshotConditionType string
+
+const (
+	// VirtualMachineSnapshotConditionInProgress is True while the snapshot
+	// operation is in flight on vSphere.
+	VirtualMachineSnapshotConditionInProgress VirtualMachineSnapshotConditionType = "InProgress"
+
+	// VirtualMachineSnapshotConditionReady is True once the snapshot has been
+	// created and SnapshotID is populated.
+	VirtualMachineSnapshotConditionReady VirtualMachineSnapshotConditionType = "Ready"
+
+	// VirtualMachineSnapshotConditionFailed is True if the snapshot operation
+	// could not be completed.
+	VirtualMachineSnapshotConditionFailed VirtualMachineSnapshotConditionType = "Failed"
+)
+
+// VirtualMachineSnapshotCondition describes one aspect of a
+// VirtualMachineSnapshot's current state.
+type VirtualMachineSnapshotCondition struct {
+	Type   VirtualMachineSnapshotConditionType `json:"type"`
+	Status string                              `json:"status"`
+
+	// Reason is a short, machine-readable identifier for why the condition
+	// has this status, e.g. the underlying vSphere fault kind for the
+	// Failed condition. Empty unless Status carries a specific cause.
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// VirtualMachineSnapshotSpec defines the desired state of a
+// VirtualMachineSnapshot.
+type VirtualMachineSnapshotSpec struct {
+	// VirtualMachineName is the name of the VirtualMachine, in the same
+	// namespace, this snapshot is taken of.
+	VirtualMachineName string `json:"virtualMachineName"`
+
+	// ParentSnapshotName optionally names another VirtualMachineSnapshot, in
+	// the same namespace, that this one is a descendant of. The controller
+	// records this lineage as an owner reference on the child, so the
+	// parent's DeletionPolicy (Retain, the default) blocks its own removal
+	// until this snapshot (and any other children) are gone.
+	ParentSnapshotName string `json:"parentSnapshotName,omitempty"`
+
+	// IncludeMemory requests that the VM's memory state be captured along
+	// with its disks.
+	IncludeMemory bool `json:"includeMemory,omitempty"`
+
+	// Quiesce requests that the guest OS's filesystems be quiesced before
+	// the snapshot is taken.
+	Quiesce bool `json:"quiesce,omitempty"`
+
+	// Description is a human-readable description of the snapshot, recorded
+	// on the underlying vSphere snapshot.
+	Description string `json:"description,omitempty"`
+
+	// RetentionLimit bounds how many snapshots the VirtualMachineName may
+	// keep. Once reached, the oldest snapshot is removed to make room for a
+	// new one. Defaults to a provider-chosen limit when unset or zero.
+	RetentionLimit int32 `json:"retentionLimit,omitempty"`
+
+	// DeletionPolicy controls whether dependent snapshots block this one's
+	// deletion (Retain, the default) or are removed along with it (Cascade).
+	DeletionPolicy VirtualMachineSnapshotDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// VirtualMachineSnapshotStatus defines the observed state of a
+// VirtualMachineSnapshot.
+type VirtualMachineSnapshotStatus struct {
+	// SnapshotID is the moref of the underlying vSphere VirtualMachineSnapshot.
+	SnapshotID string `json:"snapshotID,omitempty"`
+
+	Conditions []VirtualMachineSnapshotCondition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// VirtualMachineSnapshot is the schema for the virtualmachinesnapshots API.
+type VirtualMachineSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineSnapshotSpec   `json:"spec,omitempty"`
+	Status VirtualMachineSnapshotStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineSnapshotList contains a list of VirtualMachineSnapshot.
+type VirtualMachineSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineSnapshot `json:"items"`
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	isoSectorSize = 2048
+	isoVolumeID   = "cidata"
+)
+
+// isoFile is a single file written into the NoCloud image's root directory.
+// name must already be a valid ISO 9660 Level 1 (8.3, uppercase) identifier;
+// realName is its true, un-mangled name, recorded as a Rock Ridge "NM" entry
+// so NoCloud's exact-name datasource lookup (user-data, meta-data,
+// network-config) finds it without relying on the 8.3 identifier at all.
+type isoFile struct {
+	name     string
+	realName string
+	data     []byte
+}
+
+// BuildNoCloudISO builds a minimal, flat (single-directory) ISO 9660 Level 1
+// image containing the cloud-init NoCloud datasource's well-known files:
+// user-data, meta-data, and (if non-empty) network-config. The volume label is
+// "cidata", which is what the NoCloud datasource scans attached block devices
+// for.
+//
+// This is a hand-rolled writer rather than a dependency because the payload
+// here is always a handful of KB of YAML in a flat directory, well short of
+// needing a general-purpose ISO9660 implementation. The datasource looks up
+// files by their exact, lowercase names, which Level 1's 8.3 uppercase
+// identifiers can't represent, so every file's directory record also carries a
+// Rock Ridge (SUSP) "NM" system use entry giving the real name; genisoimage's
+// "-rock" output does the same thing for the same reason.
+func BuildNoCloudISO(metadata, userdata, networkConfig string) ([]byte, error) {
+	files := []isoFile{
+		{name: "META_DAT.;1", realName: "meta-data", data: []byte(metadata)},
+		{name: "USER_DAT.;1", realName: "user-data", data: []byte(userdata)},
+	}
+	if networkConfig != "" {
+		files = append(files, isoFile{name: "NETWORK.;1", realName: "network-config", data: []byte(networkConfig)})
+	}
+
+	// Layout, in LBAs (logical blocks of isoSectorSize bytes):
+	//   0-15   : system area (unused, zero-filled)
+	//   16     : primary volume descriptor
+	//   17     : volume descriptor set terminator
+	//   18     : path table (type L, little-endian), one sector is ample
+	//   19     : path table (type M, big-endian)
+	//   20     : root directory extent
+	//   21..   : file data, one extent run per file, padded to a sector
+	const (
+		pvdLBA        = 16
+		termLBA       = 17
+		pathTableLLBA = 18
+		pathTableMLBA = 19
+		rootDirLBA    = 20
+		firstFileLBA  = 21
+	)
+
+	fileLBAs := make([]uint32, len(files))
+	nextLBA := uint32(firstFileLBA)
+	for i, f := range files {
+		fileLBAs[i] = nextLBA
+		nextLBA += sectorsFor(len(f.data))
+	}
+	totalSectors := nextLBA
+
+	rootDirData := buildRootDirectory(rootDirLBA, files, fileLBAs)
+	pathTable := buildPathTable(rootDirLBA)
+
+	var out bytes.Buffer
+	out.Write(make([]byte, isoSectorSize*pvdLBA)) // system area
+
+	out.Write(buildPrimaryVolumeDescriptor(primaryVolumeDescriptorArgs{
+		volumeID:      isoVolumeID,
+		totalSectors:  totalSectors,
+		pathTableSize: uint32(len(pathTable)),
+		pathTableLLBA: pathTableLLBA,
+		pathTableMLBA: pathTableMLBA,
+		rootDirLBA:    rootDirLBA,
+		rootDirSize:   uint32(len(rootDirData)),
+	}))
+	out.Write(buildVolumeDescriptorSetTerminator())
+
+	if err := expectOffset(&out, isoSectorSize*pathTableLLBA); err != nil {
+		return nil, err
+	}
+	out.Write(padToSector(pathTable)) // type L
+	if err := expectOffset(&out, isoSectorSize*pathTableMLBA); err != nil {
+		return nil, err
+	}
+	out.Write(padToSector(swapPathTableEndian(pathTable))) // type M
+
+	if err := expectOffset(&out, isoSectorSize*rootDirLBA); err != nil {
+		return nil, err
+	}
+	out.Write(padToSector(rootDirData))
+
+	for i, f := range files {
+		if err := expectOffset(&out, isoSectorSize*fileLBAs[i]); err != nil {
+			return nil, err
+		}
+		out.Write(padToSector(f.data))
+	}
+
+	return out.Bytes(), nil
+}
+
+func expectOffset(buf *bytes.Buffer, want uint32) error {
+	if uint32(buf.Len()) != want {
+		return fmt.Errorf("internal error: ISO extent misaligned, got offset %d want %d", buf.Len(), want)
+	}
+	return nil
+}
+
+func sectorsFor(n int) uint32 {
+	return uint32((n + isoSectorSize - 1) / isoSectorSize)
+}
+
+func padToSector(b []byte) []byte {
+	pad := (isoSectorSize - len(b)%isoSectorSize) % isoSectorSize
+	out := make([]byte, len(b)+pad)
+	copy(out, b)
+	return out
+}
+
+// both32 writes a 32-bit value in ISO 9660's "both-byte-order" form: the
+// little-endian encoding followed by the big-endian encoding.
+func both32(v uint32) []byte {
+	return []byte{
+		byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24),
+		byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v),
+	}
+}
+
+func both16(v uint16) []byte {
+	return []byte{byte(v), byte(v >> 8), byte(v >> 8), byte(v)}
+}
+
+func fixedASCII(s string, n int) []byte {
+	b := bytes.Repeat([]byte{' '}, n)
+	copy(b, s)
+	return b
+}
+
+func buildRootDirectory(rootLBA uint32, files []isoFile, fileLBAs []uint32) []byte {
+	var buf bytes.Buffer
+
+	// SUSP requires the "SP" system use entry to be the first entry of the
+	// "." record of the root directory, announcing that Rock Ridge entries
+	// follow elsewhere in the image.
+	writeDirRecord(&buf, "\x00", rootLBA, isoSectorSize, true, rockRidgeSP()) // "."
+	writeDirRecord(&buf, "\x01", rootLBA, isoSectorSize, true, nil)           // ".."
+
+	for i, f := range files {
+		writeDirRecord(&buf, f.name, fileLBAs[i], uint32(len(f.data)), false, rockRidgeNM(f.realName))
+	}
+
+	return buf.Bytes()
+}
+
+func writeDirRecord(buf *bytes.Buffer, name string, lba, size uint32, isDir bool, systemUse []byte) {
+	idLen := len(name)
+	base := 33 + idLen
+	pad := 0
+	if idLen%2 == 0 {
+		pad = 1 // padding field so the system use area starts at an even offset
+	}
+
+	recLen := base + pad + len(systemUse)
+	if recLen%2 != 0 {
+		recLen++ // trailing pad byte to keep the whole record even-length
+	}
+
+	rec := make([]byte, recLen)
+	rec[0] = byte(recLen)
+	copy(rec[2:10], both32(lba))
+	copy(rec[10:18], both32(size))
+	copy(rec[18:25], isoDateTime())
+	if isDir {
+		rec[25] = 2
+	}
+	copy(rec[28:32], both16(1)) // volume sequence number, both-byte-order
+	rec[32] = byte(idLen)
+	copy(rec[33:33+idLen], name)
+	copy(rec[base+pad:], systemUse)
+
+	buf.Write(rec)
+}
+
+// rockRidgeSP builds the SUSP "SP" system use entry that must appear first in
+// the "." record of the root directory, signalling that Rock Ridge (SUSP)
+// entries are present elsewhere in the image.
+func rockRidgeSP() []byte {
+	return []byte{'S', 'P', 7, 1, 0xBE, 0xEF, 0}
+}
+
+// rockRidgeNM builds a Rock Ridge "NM" (alternate name) system use entry
+// recording name in full, so readers that understand Rock Ridge resolve the
+// directory record to name instead of its mangled 8.3 identifier. Returns nil
+// if name is empty, leaving the directory record with no system use area.
+func rockRidgeNM(name string) []byte {
+	if name == "" {
+		return nil
+	}
+
+	entry := make([]byte, 5+len(name))
+	entry[0], entry[1] = 'N', 'M'
+	entry[2] = byte(len(entry))
+	entry[3] = 1 // version
+	entry[4] = 0 // flags: name fits in this single entry
+	copy(entry[5:], name)
+
+	return entry
+}
+
+// isoDateTime is the 7-byte "recording date and time" used in directory
+// records. A zeroed value (meaning "not specified") is valid per the spec and
+// avoids pulling in a clock dependency for a deterministic build artifact.
+func isoDateTime() []byte {
+	return make([]byte, 7)
+}
+
+// buildPathTable emits a single type-L (little-endian) path table record for
+// the root directory, located at rootLBA. Every file we write lives directly
+// under the root, so no further entries are required.
+func buildPathTable(rootLBA uint32) []byte {
+	rec := make([]byte, 10)
+	rec[0] = 1                          // directory identifier length
+	rec[1] = 0                          // extended attribute record length
+	copy(rec[2:6], both32(rootLBA)[:4]) // LBA of root directory, little-endian
+	rec[6] = 1                          // parent directory number (root is its own parent)
+	rec[8] = 0                          // directory identifier: root is represented by a single 0x00 byte
+	return rec
+}
+
+func swapPathTableEndian(le []byte) []byte {
+	be := append([]byte{}, le...)
+	be[2], be[3], be[4], be[5] = be[5], be[4], be[3], be[2]
+	be[6], be[7] = be[7], be[6]
+	return be
+}
+
+type primaryVolumeDescriptorArgs struct {
+	volumeID      string
+	totalSectors  uint32
+	pathTableSize uint32
+	pathTableLLBA uint32
+	pathTableMLBA uint32
+	rootDirLBA    uint32
+	rootDirSize   uint32
+}
+
+func buildPrimaryVolumeDescriptor(a primaryVolumeDescriptorArgs) []byte {
+	pvd := make([]byte, isoSectorSize)
+	pvd[0] = 1 // volume descriptor type: primary
+	copy(pvd[1:6], "CD001")
+	pvd[6] = 1 // version
+
+	copy(pvd[8:40], fixedASCII("", 32))
+	copy(pvd[40:72], fixedASCII(a.volumeID, 32))
+	copy(pvd[80:88], both32(a.totalSectors))
+	copy(pvd[120:124], both16(1))
+	copy(pvd[124:128], both16(1))
+	copy(pvd[128:132], both16(isoSectorSize))
+	copy(pvd[132:140], both32(a.pathTableSize))
+	pvd[140] = byte(a.pathTableLLBA)
+	pvd[141] = byte(a.pathTableLLBA >> 8)
+	pvd[142] = byte(a.pathTableLLBA >> 16)
+	pvd[143] = byte(a.pathTableLLBA >> 24)
+	pvd[148] = byte(a.pathTableMLBA >> 24)
+	pvd[149] = byte(a.pathTableMLBA >> 16)
+	pvd[150] = byte(a.pathTableMLBA >> 8)
+	pvd[151] = byte(a.pathTableMLBA)
+
+	rootRec := make([]byte, 34)
+	rootRec[0] = 34
+	copy(rootRec[2:10], both32(a.rootDirLBA))
+	copy(rootRec[10:18], both32(a.rootDirSize))
+	copy(rootRec[18:25], isoDateTime())
+	rootRec[25] = 2                 // directory flag
+	copy(rootRec[28:32], both16(1)) // volume sequence number, both-byte-order
+	rootRec[32] = 1
+	rootRec[33] = 0 // root directory's self-identifier
+	copy(pvd[156:190], rootRec)
+
+	copy(pvd[190:318], fixedASCII("", 128))
+	copy(pvd[318:446], fixedASCII("", 128))
+	copy(pvd[446:574], fixedASCII("", 128))
+	copy(pvd[574:702], fixedASCII("VM-OPERATOR", 128))
+	pvd[881] = 1 // file structure version
+
+	return pvd
+}
+
+func buildVolumeDescriptorSetTerminator() []byte {
+	b := make([]byte, isoSectorSize)
+	b[0] = 255
+	copy(b[1:6], "CD001")
+	b[6] = 1
+	return b
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuildNoCloudISOContainsFileData(t *testing.T) {
+	iso, err := BuildNoCloudISO("meta: data", "user: data", "network: config")
+	if err != nil {
+		t.Fatalf("BuildNoCloudISO() error = %v", err)
+	}
+
+	for _, want := range []string{"meta: data", "user: data", "network: config"} {
+		if !bytes.Contains(iso, []byte(want)) {
+			t.Errorf("output does not contain file data %q", want)
+		}
+	}
+
+	if !bytes.Contains(iso, []byte(isoVolumeID)) {
+		t.Errorf("output does not contain volume ID %q", isoVolumeID)
+	}
+}
+
+func TestBuildNoCloudISOOmitsEmptyNetworkConfig(t *testing.T) {
+	iso, err := BuildNoCloudISO("meta: data", "user: data", "")
+	if err != nil {
+		t.Fatalf("BuildNoCloudISO() error = %v", err)
+	}
+
+	if bytes.Contains(iso, []byte("network-config")) {
+		t.Errorf("output contains a network-config entry despite an empty networkConfig argument")
+	}
+}
+
+// TestBuildNoCloudISORockRidgeNames verifies that every file's real,
+// un-mangled name (which the NoCloud datasource looks up exactly) survives as
+// a Rock Ridge "NM" system use entry on its directory record, not just the
+// mangled 8.3 identifier.
+func TestBuildNoCloudISORockRidgeNames(t *testing.T) {
+	iso, err := BuildNoCloudISO("metadata", "userdata", "network-config")
+	if err != nil {
+		t.Fatalf("BuildNoCloudISO() error = %v", err)
+	}
+
+	rootDir := iso[isoSectorSize*20 : isoSectorSize*20+isoSectorSize]
+
+	gotNames := map[string]bool{}
+	for off := 0; off < len(rootDir); {
+		recLen := int(rootDir[off])
+		if recLen == 0 {
+			break
+		}
+		rec := rootDir[off : off+recLen]
+
+		idLen := int(rec[32])
+		base := 33 + idLen
+		pad := 0
+		if idLen%2 == 0 {
+			pad = 1
+		}
+		if base+pad < len(rec) {
+			su := rec[base+pad:]
+			if len(su) >= 5 && su[0] == 'N' && su[1] == 'M' {
+				nameLen := int(su[2]) - 5
+				gotNames[string(su[5:5+nameLen])] = true
+			}
+		}
+
+		off += recLen
+	}
+
+	for _, want := range []string{"meta-data", "user-data", "network-config"} {
+		if !gotNames[want] {
+			t.Errorf("root directory missing Rock Ridge NM entry for %q, got %v", want, gotNames)
+		}
+	}
+}
+
+func TestBuildNoCloudISORootSPEntry(t *testing.T) {
+	iso, err := BuildNoCloudISO("metadata", "userdata", "")
+	if err != nil {
+		t.Fatalf("BuildNoCloudISO() error = %v", err)
+	}
+
+	rootDir := iso[isoSectorSize*20 : isoSectorSize*20+isoSectorSize]
+
+	// The "." record is always first; its system use area starts right after
+	// the (odd-length, so unpadded) single-byte "\x00" identifier.
+	dotRecLen := int(rootDir[0])
+	dotRec := rootDir[:dotRecLen]
+	su := dotRec[33+1:] // idLen=1 is odd, so no padding field
+	if len(su) < 2 || su[0] != 'S' || su[1] != 'P' {
+		t.Errorf("\".\" record missing leading SUSP \"SP\" entry, system use area = %v", su)
+	}
+}
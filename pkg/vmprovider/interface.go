@@ -9,6 +9,8 @@ import (
 	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
 
 	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+	topologyv1a1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
+	snapshotv1a1 "github.com/vmware-tanzu/vm-operator/external/vm-operator-api/api/v1alpha1"
 )
 
 // VirtualMachineProviderInterface is a plugable interface for VM Providers.
@@ -24,6 +26,12 @@ type VirtualMachineProviderInterface interface {
 	IsVirtualMachineSetResourcePolicyReady(ctx context.Context, availabilityZoneName string, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) (bool, error)
 	DeleteVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) error
 
+	// Snapshot related
+	CreateVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error
+	DeleteVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error
+	RevertVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error
+	ListVirtualMachineSnapshots(ctx context.Context, vm *v1alpha1.VirtualMachine) ([]snapshotv1a1.VirtualMachineSnapshot, error)
+
 	// "Infra" related
 	UpdateVcPNID(ctx context.Context, vcPNID, vcPort string) error
 	ClearSessionsAndClient(ctx context.Context)
@@ -33,4 +41,65 @@ type VirtualMachineProviderInterface interface {
 	ListItemsFromContentLibrary(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider) ([]string, error)
 	GetVirtualMachineImageFromContentLibrary(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider, itemID string,
 		currentCLImages map[string]v1alpha1.VirtualMachineImage) (*v1alpha1.VirtualMachineImage, error)
+
+	// GetAvailabilityZoneCapacity returns az's current capacity and utilization,
+	// aggregated across the vSphere clusters backing it.
+	GetAvailabilityZoneCapacity(ctx context.Context, az *topologyv1a1.AvailabilityZone) (AvailabilityZoneCapacity, error)
+}
+
+// AvailabilityZoneCapacity is the aggregate capacity and utilization of an
+// AvailabilityZone, along with the per-cluster breakdown it was computed from,
+// as reported by GetAvailabilityZoneCapacity. CPU is in MHz and memory is in
+// MB, matching the units govmomi reports them in.
+type AvailabilityZoneCapacity struct {
+	TotalCPU    int64
+	TotalMemory int64
+	UsedCPU     int64
+	UsedMemory  int64
+	VMCount     int32
+
+	Clusters []ClusterCapacity
+}
+
+// ClusterCapacity is the capacity and utilization of a single vSphere cluster
+// backing an AvailabilityZone.
+type ClusterCapacity struct {
+	ClusterComputeResourceMoId string
+	TotalCPU                   int64
+	TotalMemory                int64
+	UsedCPU                    int64
+	UsedMemory                 int64
+	VMCount                    int32
 }
+
+// SnapshotFaultReason classifies why a CreateVirtualMachineSnapshot,
+// DeleteVirtualMachineSnapshot, or RevertVirtualMachineSnapshot call failed,
+// so callers can react to the kind of failure programmatically instead of
+// string-matching the error message.
+type SnapshotFaultReason string
+
+const (
+	// SnapshotFaultReasonLocked means the VM or one of its disks was locked by
+	// another operation already in progress.
+	SnapshotFaultReasonLocked SnapshotFaultReason = "Locked"
+	// SnapshotFaultReasonInvalidState means the VM was in a state that
+	// doesn't support the requested snapshot operation (e.g. suspended).
+	SnapshotFaultReasonInvalidState SnapshotFaultReason = "InvalidState"
+	// SnapshotFaultReasonNotSupported means the VM, or a device attached to
+	// it, doesn't support the requested operation.
+	SnapshotFaultReasonNotSupported SnapshotFaultReason = "NotSupported"
+	// SnapshotFaultReasonGeneric is used when the failure doesn't match any
+	// of the more specific reasons above.
+	SnapshotFaultReasonGeneric SnapshotFaultReason = "Generic"
+)
+
+// SnapshotFaultError wraps a snapshot operation failure with the
+// SnapshotFaultReason a caller can switch on via errors.As, without parsing
+// the error message.
+type SnapshotFaultError struct {
+	Reason SnapshotFaultReason
+	Err    error
+}
+
+func (e *SnapshotFaultError) Error() string { return e.Err.Error() }
+func (e *SnapshotFaultError) Unwrap() error { return e.Err }
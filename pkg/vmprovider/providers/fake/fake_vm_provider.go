@@ -0,0 +1,189 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fake provides a fake vmprovider.VirtualMachineProviderInterface for
+// use in unit tests, following the same override-a-func-field pattern as the
+// rest of the provider's test doubles: every method is backed by a Fn field
+// that defaults to a harmless zero-value implementation and can be swapped
+// out per-test.
+package fake
+
+import (
+	"context"
+
+	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
+
+	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+	topologyv1a1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
+	snapshotv1a1 "github.com/vmware-tanzu/vm-operator/external/vm-operator-api/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+// VMProvider is a fake vmprovider.VirtualMachineProviderInterface. The zero
+// value is ready to use: every method no-ops and returns a nil error. Tests
+// that care about a particular call should set the matching Fn field.
+type VMProvider struct {
+	CreateOrUpdateVirtualMachineFn func(ctx context.Context, vm *v1alpha1.VirtualMachine) error
+	DeleteVirtualMachineFn         func(ctx context.Context, vm *v1alpha1.VirtualMachine) error
+	PublishVirtualMachineFn        func(ctx context.Context, vm *v1alpha1.VirtualMachine, vmPub *v1alpha1.VirtualMachinePublishRequest,
+		cl *imgregv1a1.ContentLibrary) (string, error)
+	GetVirtualMachineGuestHeartbeatFn func(ctx context.Context, vm *v1alpha1.VirtualMachine) (v1alpha1.GuestHeartbeatStatus, error)
+	GetVirtualMachineWebMKSTicketFn   func(ctx context.Context, vm *v1alpha1.VirtualMachine, pubKey string) (string, error)
+
+	CreateOrUpdateVirtualMachineSetResourcePolicyFn func(ctx context.Context, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) error
+	IsVirtualMachineSetResourcePolicyReadyFn        func(ctx context.Context, availabilityZoneName string, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) (bool, error)
+	DeleteVirtualMachineSetResourcePolicyFn         func(ctx context.Context, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) error
+
+	CreateVirtualMachineSnapshotFn func(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error
+	DeleteVirtualMachineSnapshotFn func(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error
+	RevertVirtualMachineSnapshotFn func(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error
+	ListVirtualMachineSnapshotsFn  func(ctx context.Context, vm *v1alpha1.VirtualMachine) ([]snapshotv1a1.VirtualMachineSnapshot, error)
+
+	UpdateVcPNIDFn                func(ctx context.Context, vcPNID, vcPort string) error
+	ClearSessionsAndClientFn      func(ctx context.Context)
+	DeleteNamespaceSessionInCacheFn func(ctx context.Context, namespace string) error
+	ComputeCPUMinFrequencyFn      func(ctx context.Context) error
+
+	ListItemsFromContentLibraryFn func(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider) ([]string, error)
+	GetVirtualMachineImageFromContentLibraryFn func(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider, itemID string,
+		currentCLImages map[string]v1alpha1.VirtualMachineImage) (*v1alpha1.VirtualMachineImage, error)
+
+	GetAvailabilityZoneCapacityFn func(ctx context.Context, az *topologyv1a1.AvailabilityZone) (vmprovider.AvailabilityZoneCapacity, error)
+}
+
+var _ vmprovider.VirtualMachineProviderInterface = &VMProvider{}
+
+func (p *VMProvider) CreateOrUpdateVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine) error {
+	if p.CreateOrUpdateVirtualMachineFn != nil {
+		return p.CreateOrUpdateVirtualMachineFn(ctx, vm)
+	}
+	return nil
+}
+
+func (p *VMProvider) DeleteVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine) error {
+	if p.DeleteVirtualMachineFn != nil {
+		return p.DeleteVirtualMachineFn(ctx, vm)
+	}
+	return nil
+}
+
+func (p *VMProvider) PublishVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine, vmPub *v1alpha1.VirtualMachinePublishRequest,
+	cl *imgregv1a1.ContentLibrary) (string, error) {
+	if p.PublishVirtualMachineFn != nil {
+		return p.PublishVirtualMachineFn(ctx, vm, vmPub, cl)
+	}
+	return "", nil
+}
+
+func (p *VMProvider) GetVirtualMachineGuestHeartbeat(ctx context.Context, vm *v1alpha1.VirtualMachine) (v1alpha1.GuestHeartbeatStatus, error) {
+	if p.GetVirtualMachineGuestHeartbeatFn != nil {
+		return p.GetVirtualMachineGuestHeartbeatFn(ctx, vm)
+	}
+	return "", nil
+}
+
+func (p *VMProvider) GetVirtualMachineWebMKSTicket(ctx context.Context, vm *v1alpha1.VirtualMachine, pubKey string) (string, error) {
+	if p.GetVirtualMachineWebMKSTicketFn != nil {
+		return p.GetVirtualMachineWebMKSTicketFn(ctx, vm, pubKey)
+	}
+	return "", nil
+}
+
+func (p *VMProvider) CreateOrUpdateVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) error {
+	if p.CreateOrUpdateVirtualMachineSetResourcePolicyFn != nil {
+		return p.CreateOrUpdateVirtualMachineSetResourcePolicyFn(ctx, resourcePolicy)
+	}
+	return nil
+}
+
+func (p *VMProvider) IsVirtualMachineSetResourcePolicyReady(ctx context.Context, availabilityZoneName string,
+	resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) (bool, error) {
+	if p.IsVirtualMachineSetResourcePolicyReadyFn != nil {
+		return p.IsVirtualMachineSetResourcePolicyReadyFn(ctx, availabilityZoneName, resourcePolicy)
+	}
+	return true, nil
+}
+
+func (p *VMProvider) DeleteVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) error {
+	if p.DeleteVirtualMachineSetResourcePolicyFn != nil {
+		return p.DeleteVirtualMachineSetResourcePolicyFn(ctx, resourcePolicy)
+	}
+	return nil
+}
+
+func (p *VMProvider) CreateVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error {
+	if p.CreateVirtualMachineSnapshotFn != nil {
+		return p.CreateVirtualMachineSnapshotFn(ctx, vm, snap)
+	}
+	return nil
+}
+
+func (p *VMProvider) DeleteVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error {
+	if p.DeleteVirtualMachineSnapshotFn != nil {
+		return p.DeleteVirtualMachineSnapshotFn(ctx, vm, snap)
+	}
+	return nil
+}
+
+func (p *VMProvider) RevertVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, snap *snapshotv1a1.VirtualMachineSnapshot) error {
+	if p.RevertVirtualMachineSnapshotFn != nil {
+		return p.RevertVirtualMachineSnapshotFn(ctx, vm, snap)
+	}
+	return nil
+}
+
+func (p *VMProvider) ListVirtualMachineSnapshots(ctx context.Context, vm *v1alpha1.VirtualMachine) ([]snapshotv1a1.VirtualMachineSnapshot, error) {
+	if p.ListVirtualMachineSnapshotsFn != nil {
+		return p.ListVirtualMachineSnapshotsFn(ctx, vm)
+	}
+	return nil, nil
+}
+
+func (p *VMProvider) UpdateVcPNID(ctx context.Context, vcPNID, vcPort string) error {
+	if p.UpdateVcPNIDFn != nil {
+		return p.UpdateVcPNIDFn(ctx, vcPNID, vcPort)
+	}
+	return nil
+}
+
+func (p *VMProvider) ClearSessionsAndClient(ctx context.Context) {
+	if p.ClearSessionsAndClientFn != nil {
+		p.ClearSessionsAndClientFn(ctx)
+	}
+}
+
+func (p *VMProvider) DeleteNamespaceSessionInCache(ctx context.Context, namespace string) error {
+	if p.DeleteNamespaceSessionInCacheFn != nil {
+		return p.DeleteNamespaceSessionInCacheFn(ctx, namespace)
+	}
+	return nil
+}
+
+func (p *VMProvider) ComputeCPUMinFrequency(ctx context.Context) error {
+	if p.ComputeCPUMinFrequencyFn != nil {
+		return p.ComputeCPUMinFrequencyFn(ctx)
+	}
+	return nil
+}
+
+func (p *VMProvider) ListItemsFromContentLibrary(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider) ([]string, error) {
+	if p.ListItemsFromContentLibraryFn != nil {
+		return p.ListItemsFromContentLibraryFn(ctx, contentLibrary)
+	}
+	return nil, nil
+}
+
+func (p *VMProvider) GetVirtualMachineImageFromContentLibrary(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider, itemID string,
+	currentCLImages map[string]v1alpha1.VirtualMachineImage) (*v1alpha1.VirtualMachineImage, error) {
+	if p.GetVirtualMachineImageFromContentLibraryFn != nil {
+		return p.GetVirtualMachineImageFromContentLibraryFn(ctx, contentLibrary, itemID, currentCLImages)
+	}
+	return nil, nil
+}
+
+func (p *VMProvider) GetAvailabilityZoneCapacity(ctx context.Context, az *topologyv1a1.AvailabilityZone) (vmprovider.AvailabilityZoneCapacity, error) {
+	if p.GetAvailabilityZoneCapacityFn != nil {
+		return p.GetAvailabilityZoneCapacityFn(ctx, az)
+	}
+	return vmprovider.AvailabilityZoneCapacity{}, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2021-2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package constants
+
+import (
+	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
+)
+
+const (
+	// GOSCPendingExtraConfigKey is the ExtraConfig key vSphere sets while a
+	// guest OS customization is in flight.
+	GOSCPendingExtraConfigKey = "tools.deployPkg.done"
+
+	// ExtraConfigGuestInfoPrefix is the ExtraConfig key prefix used for the
+	// guestinfo transport.
+	ExtraConfigGuestInfoPrefix = "guestinfo."
+
+	// CloudInitGuestInfoMetadata and CloudInitGuestInfoMetadataEncoding are the
+	// ExtraConfig keys cloud-init's guestinfo datasource reads metadata from.
+	CloudInitGuestInfoMetadata         = ExtraConfigGuestInfoPrefix + "metadata"
+	CloudInitGuestInfoMetadataEncoding = ExtraConfigGuestInfoPrefix + "metadata.encoding"
+
+	// CloudInitGuestInfoUserdata and CloudInitGuestInfoUserdataEncoding are the
+	// ExtraConfig keys cloud-init's guestinfo datasource reads userdata from.
+	CloudInitGuestInfoUserdata         = ExtraConfigGuestInfoPrefix + "userdata"
+	CloudInitGuestInfoUserdataEncoding = ExtraConfigGuestInfoPrefix + "userdata.encoding"
+
+	// CloudInitTypeAnnotation selects which cloud-init delivery mechanism is
+	// used when VMMetadata.Transport is the CloudInit transport.
+	CloudInitTypeAnnotation         = "vmoperator.vmware.com/cloudinit-type"
+	CloudInitTypeValueCloudInitPrep = "CloudInitPrep"
+	CloudInitTypeValueGuestInfo     = "GuestInfo"
+
+	// VSphereCustomizationBypassKey lets a VM opt out of vSphere guest
+	// customization entirely, e.g. when the guest image already handles its
+	// own first-boot configuration.
+	VSphereCustomizationBypassKey     = "vmoperator.vmware.com/vsphere-customization-bypass"
+	VSphereCustomizationBypassDisable = "disable"
+
+	// GuestOSFamilyAnnotation lets users force the guest family vm-operator
+	// customizes for, for guest IDs it doesn't otherwise recognize as Windows.
+	GuestOSFamilyAnnotation = "vmoperator.vmware.com/guest-os-family"
+	GuestOSFamilyWindows    = "windows"
+
+	// MetadataTemplateStrictAnnotation opts a VM into failing (rather than
+	// silently leaving unresolved) VM metadata templating errors.
+	MetadataTemplateStrictAnnotation = "vmoperator.vmware.com/metadata-template-strict"
+
+	// PlacementZoneAnnotation, PlacementClusterAnnotation, and
+	// PlacementHostAnnotation record where a VM was actually placed by its
+	// VirtualMachineSetResourcePolicy's zone spread, since VirtualMachineStatus
+	// has no placement field of its own.
+	PlacementZoneAnnotation    = "vmoperator.vmware.com/placement-zone"
+	PlacementClusterAnnotation = "vmoperator.vmware.com/placement-cluster"
+	PlacementHostAnnotation    = "vmoperator.vmware.com/placement-host"
+)
+
+// CloudInitNoCloudTransport is the VMMetadata.Transport value selecting the
+// NoCloud CD-ROM datasource instead of the guestinfo transport.
+const CloudInitNoCloudTransport v1alpha1.VirtualMachineMetadataTransport = "CloudInitNoCloud"
@@ -0,0 +1,124 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
+
+	topologyv1a1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
+)
+
+// ZoneSelection is the outcome of SelectZoneForResourcePolicyMember: which
+// zone (and, within it, which cluster) a VM should be placed in.
+type ZoneSelection struct {
+	Zone    string
+	Cluster string
+}
+
+// SelectZoneForResourcePolicyMember implements a soft anti-affinity / spread
+// policy across the zones available to a VirtualMachineSetResourcePolicy's
+// members: it picks the zone with the fewest VMs belonging to policy already
+// placed in it, breaking ties by the most free effective CPU. This keeps
+// replicas of a workload spread across failure domains instead of piling into
+// whichever zone happens to go Ready first.
+func SelectZoneForResourcePolicyMember(
+	policy *v1alpha1.VirtualMachineSetResourcePolicy,
+	zones []topologyv1a1.AvailabilityZone,
+	placedCounts map[string]int32) (ZoneSelection, error) {
+
+	if len(zones) == 0 {
+		return ZoneSelection{}, fmt.Errorf("no availability zones to place resource policy %q members in", policy.Name)
+	}
+
+	candidates := make([]topologyv1a1.AvailabilityZone, len(zones))
+	copy(candidates, zones)
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := placedCounts[candidates[i].Name], placedCounts[candidates[j].Name]
+		if ci != cj {
+			return ci < cj
+		}
+		freeI := candidates[i].Status.TotalCPU - candidates[i].Status.UsedCPU
+		freeJ := candidates[j].Status.TotalCPU - candidates[j].Status.UsedCPU
+		return freeI > freeJ
+	})
+
+	best := candidates[0]
+
+	cluster, err := bestClusterInZone(best)
+	if err != nil {
+		return ZoneSelection{}, err
+	}
+
+	return ZoneSelection{Zone: best.Name, Cluster: cluster}, nil
+}
+
+// bestClusterInZone picks the cluster within zone with the most free
+// effective CPU, so a single zone's own clusters also spread evenly.
+func bestClusterInZone(zone topologyv1a1.AvailabilityZone) (string, error) {
+	if len(zone.Status.ClusterStatus) == 0 {
+		return "", fmt.Errorf("availability zone %q has no cluster capacity reported yet", zone.Name)
+	}
+
+	best := zone.Status.ClusterStatus[0]
+	for _, c := range zone.Status.ClusterStatus[1:] {
+		if (c.TotalCPU - c.UsedCPU) > (best.TotalCPU - best.UsedCPU) {
+			best = c
+		}
+	}
+
+	return best.ClusterComputeResourceMoId, nil
+}
+
+// ApplyZoneSelection records sel as placement annotations on vm, so
+// higher-level controllers (e.g. Cluster API) and users can see where a VM
+// actually landed, not just where its resource policy was eligible to place
+// it. VirtualMachineStatus, as vendored into this tree, has no placement
+// field of its own to set instead, so this follows the same annotation
+// convention as the rest of this provider (see constants.GuestOSFamilyAnnotation
+// and friends) rather than a genuine status subresource. host is filled in
+// separately, once the vSphere provider knows which ESXi host the VM's been
+// registered on.
+func ApplyZoneSelection(vm *v1alpha1.VirtualMachine, sel ZoneSelection, host string) {
+	if vm.Annotations == nil {
+		vm.Annotations = map[string]string{}
+	}
+	vm.Annotations[constants.PlacementZoneAnnotation] = sel.Zone
+	vm.Annotations[constants.PlacementClusterAnnotation] = sel.Cluster
+	if host != "" {
+		vm.Annotations[constants.PlacementHostAnnotation] = host
+	}
+}
+
+// PlaceResourcePolicyMember selects a zone and cluster for vm, a member of
+// policy, via SelectZoneForResourcePolicyMember, and records the outcome on
+// vm via ApplyZoneSelection. host is empty until the vSphere provider
+// registers vm on a specific ESXi host; callers that already know it can
+// pass it through so it's recorded in the same annotation update.
+//
+// NOTE: this is not yet called from a VirtualMachineSetResourcePolicy
+// reconcile loop. This tree has no such controller (see controllers/, which
+// only has availabilityzone and virtualmachinesnapshot); wiring
+// PlaceResourcePolicyMember into the real placement path is left to that
+// controller when it's added. Treat zone-spread placement as implemented but
+// not yet plumbed end-to-end.
+func PlaceResourcePolicyMember(
+	vm *v1alpha1.VirtualMachine,
+	policy *v1alpha1.VirtualMachineSetResourcePolicy,
+	zones []topologyv1a1.AvailabilityZone,
+	placedCounts map[string]int32,
+	host string) (ZoneSelection, error) {
+
+	sel, err := SelectZoneForResourcePolicyMember(policy, zones, placedCounts)
+	if err != nil {
+		return ZoneSelection{}, err
+	}
+
+	ApplyZoneSelection(vm, sel, host)
+	return sel, nil
+}
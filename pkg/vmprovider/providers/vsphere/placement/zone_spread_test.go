@@ -0,0 +1,106 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
+
+	topologyv1a1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
+)
+
+func zoneWithCapacity(name string, totalCPU, usedCPU int64) topologyv1a1.AvailabilityZone {
+	return topologyv1a1.AvailabilityZone{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: topologyv1a1.AvailabilityZoneStatus{
+			TotalCPU: totalCPU,
+			UsedCPU:  usedCPU,
+			ClusterStatus: []topologyv1a1.ClusterStatus{
+				{ClusterComputeResourceMoId: name + "-cluster", TotalCPU: totalCPU, UsedCPU: usedCPU},
+			},
+		},
+	}
+}
+
+func TestSelectZoneForResourcePolicyMemberPrefersFewestPlaced(t *testing.T) {
+	zones := []topologyv1a1.AvailabilityZone{
+		zoneWithCapacity("zone-a", 1000, 100),
+		zoneWithCapacity("zone-b", 1000, 900),
+	}
+	placedCounts := map[string]int32{"zone-a": 3, "zone-b": 1}
+
+	policy := &v1alpha1.VirtualMachineSetResourcePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}}
+
+	sel, err := SelectZoneForResourcePolicyMember(policy, zones, placedCounts)
+	if err != nil {
+		t.Fatalf("SelectZoneForResourcePolicyMember() error = %v", err)
+	}
+	if sel.Zone != "zone-b" {
+		t.Errorf("Zone = %q, want %q (fewest placed members)", sel.Zone, "zone-b")
+	}
+	if sel.Cluster != "zone-b-cluster" {
+		t.Errorf("Cluster = %q, want %q", sel.Cluster, "zone-b-cluster")
+	}
+}
+
+func TestSelectZoneForResourcePolicyMemberBreaksTiesByFreeCPU(t *testing.T) {
+	zones := []topologyv1a1.AvailabilityZone{
+		zoneWithCapacity("zone-a", 1000, 800),
+		zoneWithCapacity("zone-b", 1000, 200),
+	}
+	placedCounts := map[string]int32{"zone-a": 1, "zone-b": 1}
+
+	policy := &v1alpha1.VirtualMachineSetResourcePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}}
+
+	sel, err := SelectZoneForResourcePolicyMember(policy, zones, placedCounts)
+	if err != nil {
+		t.Fatalf("SelectZoneForResourcePolicyMember() error = %v", err)
+	}
+	if sel.Zone != "zone-b" {
+		t.Errorf("Zone = %q, want %q (most free CPU)", sel.Zone, "zone-b")
+	}
+}
+
+func TestSelectZoneForResourcePolicyMemberNoZones(t *testing.T) {
+	policy := &v1alpha1.VirtualMachineSetResourcePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}}
+
+	if _, err := SelectZoneForResourcePolicyMember(policy, nil, nil); err == nil {
+		t.Errorf("SelectZoneForResourcePolicyMember() error = nil, want error for no zones")
+	}
+}
+
+func TestPlaceResourcePolicyMemberRecordsAnnotations(t *testing.T) {
+	zones := []topologyv1a1.AvailabilityZone{zoneWithCapacity("zone-a", 1000, 100)}
+	policy := &v1alpha1.VirtualMachineSetResourcePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}}
+	vm := &v1alpha1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "vm-1"}}
+
+	sel, err := PlaceResourcePolicyMember(vm, policy, zones, nil, "esxi-1")
+	if err != nil {
+		t.Fatalf("PlaceResourcePolicyMember() error = %v", err)
+	}
+
+	if got := vm.Annotations[constants.PlacementZoneAnnotation]; got != sel.Zone {
+		t.Errorf("PlacementZoneAnnotation = %q, want %q", got, sel.Zone)
+	}
+	if got := vm.Annotations[constants.PlacementClusterAnnotation]; got != sel.Cluster {
+		t.Errorf("PlacementClusterAnnotation = %q, want %q", got, sel.Cluster)
+	}
+	if got := vm.Annotations[constants.PlacementHostAnnotation]; got != "esxi-1" {
+		t.Errorf("PlacementHostAnnotation = %q, want %q", got, "esxi-1")
+	}
+}
+
+func TestApplyZoneSelectionOmitsEmptyHost(t *testing.T) {
+	vm := &v1alpha1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "vm-1"}}
+
+	ApplyZoneSelection(vm, ZoneSelection{Zone: "zone-a", Cluster: "cluster-a"}, "")
+
+	if _, ok := vm.Annotations[constants.PlacementHostAnnotation]; ok {
+		t.Errorf("PlacementHostAnnotation set with an empty host")
+	}
+}
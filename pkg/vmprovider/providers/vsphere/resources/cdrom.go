@@ -0,0 +1,176 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"sort"
+
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+// noCloudCDLabel tags the CD-ROM device/backing we add for the NoCloud ISO, so
+// DetachAndDeleteNoCloudISO can find it again without assuming it's always the
+// VM's only (or first) CD-ROM device.
+const noCloudCDLabel = "nocloud-cidata"
+
+// AttachCDROMFromDatastore reconfigures vm with a virtual CD-ROM device backed
+// by the ISO image already uploaded to dsPath (a datastore path, e.g.
+// "[datastore1] vm-name/cidata.iso"). If the VM already has a CD-ROM device
+// labeled for NoCloud, its backing is swapped in place instead of adding a
+// second drive.
+func AttachCDROMFromDatastore(vmCtx context.VirtualMachineContext, resVM *VirtualMachine, dsPath string) error {
+	devices, err := resVM.GetVirtualDevices(vmCtx)
+	if err != nil {
+		return fmt.Errorf("getting virtual devices failed: %w", err)
+	}
+
+	backing := &vimTypes.VirtualCdromIsoBackingInfo{
+		VirtualDeviceFileBackingInfo: vimTypes.VirtualDeviceFileBackingInfo{
+			FileName: dsPath,
+		},
+	}
+
+	if existing := findNoCloudCDROM(devices); existing != nil {
+		existing.Backing = backing
+		existing.Connectable = &vimTypes.VirtualDeviceConnectInfo{Connected: true, StartConnected: true}
+		return resVM.Reconfigure(vmCtx, &vimTypes.VirtualMachineConfigSpec{
+			DeviceChange: []vimTypes.BaseVirtualDeviceConfigSpec{
+				&vimTypes.VirtualDeviceConfigSpec{
+					Operation: vimTypes.VirtualDeviceConfigSpecOperationEdit,
+					Device:    existing,
+				},
+			},
+		})
+	}
+
+	controllerKey, newController := findOrAddCDROMController(devices)
+
+	cdrom := &vimTypes.VirtualCdrom{
+		VirtualDevice: vimTypes.VirtualDevice{
+			Key:           -1,
+			ControllerKey: controllerKey,
+			Backing:       backing,
+			Connectable:   &vimTypes.VirtualDeviceConnectInfo{Connected: true, StartConnected: true},
+			DeviceInfo: &vimTypes.Description{
+				Label:   noCloudCDLabel,
+				Summary: "NoCloud cloud-init datasource",
+			},
+		},
+	}
+
+	deviceChange := make([]vimTypes.BaseVirtualDeviceConfigSpec, 0, 2)
+	if newController != nil {
+		deviceChange = append(deviceChange, &vimTypes.VirtualDeviceConfigSpec{
+			Operation: vimTypes.VirtualDeviceConfigSpecOperationAdd,
+			Device:    newController,
+		})
+	}
+	deviceChange = append(deviceChange, &vimTypes.VirtualDeviceConfigSpec{
+		Operation: vimTypes.VirtualDeviceConfigSpecOperationAdd,
+		Device:    cdrom,
+	})
+
+	return resVM.Reconfigure(vmCtx, &vimTypes.VirtualMachineConfigSpec{
+		DeviceChange: deviceChange,
+	})
+}
+
+// DetachAndDeleteNoCloudISO removes the NoCloud CD-ROM device (if present) and
+// deletes the backing ISO file at dsPath from the datastore, undoing
+// AttachCDROMFromDatastore. If the device was already removed, only the ISO
+// file is deleted.
+func DetachAndDeleteNoCloudISO(vmCtx context.VirtualMachineContext, resVM *VirtualMachine, dsPath string) error {
+	devices, err := resVM.GetVirtualDevices(vmCtx)
+	if err != nil {
+		return fmt.Errorf("getting virtual devices failed: %w", err)
+	}
+
+	if existing := findNoCloudCDROM(devices); existing != nil {
+		if err := resVM.Reconfigure(vmCtx, &vimTypes.VirtualMachineConfigSpec{
+			DeviceChange: []vimTypes.BaseVirtualDeviceConfigSpec{
+				&vimTypes.VirtualDeviceConfigSpec{
+					Operation: vimTypes.VirtualDeviceConfigSpecOperationRemove,
+					Device:    existing,
+				},
+			},
+		}); err != nil {
+			return fmt.Errorf("detaching NoCloud CD-ROM failed: %w", err)
+		}
+	}
+
+	if err := resVM.DeleteDatastoreFile(vmCtx, dsPath); err != nil {
+		return fmt.Errorf("deleting NoCloud ISO %q failed: %w", dsPath, err)
+	}
+
+	return nil
+}
+
+func findNoCloudCDROM(devices vimTypes.VirtualDeviceList) *vimTypes.VirtualCdrom {
+	for _, d := range devices {
+		cdrom, ok := d.(*vimTypes.VirtualCdrom)
+		if !ok {
+			continue
+		}
+		if info := cdrom.DeviceInfo.GetDescription(); info != nil && info.Label == noCloudCDLabel {
+			return cdrom
+		}
+	}
+	return nil
+}
+
+// findOrAddCDROMController returns the key of a controller devices has room
+// to attach a CD-ROM to: an existing IDE, SATA, or NVMe controller with a
+// free device slot, preferred in that order since IDE is what the NoCloud CD-ROM
+// has traditionally used. If none qualifies (e.g. an all-NVMe, fully-populated
+// VM), it returns the key of a brand-new IDE controller that the caller must
+// add to the same reconfigure as the CD-ROM device.
+func findOrAddCDROMController(devices vimTypes.VirtualDeviceList) (int32, vimTypes.BaseVirtualDevice) {
+	type candidate struct {
+		key      int32
+		priority int
+		max      int
+	}
+
+	var candidates []candidate
+	for _, d := range devices {
+		switch c := d.(type) {
+		case *vimTypes.VirtualIDEController:
+			candidates = append(candidates, candidate{key: c.Key, priority: 0, max: 2})
+		case *vimTypes.VirtualAHCIController:
+			candidates = append(candidates, candidate{key: c.Key, priority: 1, max: 30})
+		case *vimTypes.VirtualNVMEController:
+			candidates = append(candidates, candidate{key: c.Key, priority: 2, max: 15})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].priority < candidates[j].priority })
+
+	for _, c := range candidates {
+		if controllerUnitsInUse(devices, c.key) < c.max {
+			return c.key, nil
+		}
+	}
+
+	ide := &vimTypes.VirtualIDEController{
+		VirtualController: vimTypes.VirtualController{
+			VirtualDevice: vimTypes.VirtualDevice{Key: -200},
+		},
+	}
+	return ide.Key, ide
+}
+
+// controllerUnitsInUse counts how many devices are already attached to the
+// controller identified by controllerKey.
+func controllerUnitsInUse(devices vimTypes.VirtualDeviceList, controllerKey int32) int {
+	var n int
+	for _, d := range devices {
+		if d.GetVirtualDevice().ControllerKey == controllerKey {
+			n++
+		}
+	}
+	return n
+}
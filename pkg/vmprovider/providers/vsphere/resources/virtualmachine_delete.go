@@ -0,0 +1,42 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+// Delete powers off the VM, if needed, and destroys it.
+func (vm *VirtualMachine) Delete(vmCtx context.VirtualMachineContext) error {
+	var o struct {
+		Runtime vimTypes.VirtualMachineRuntimeInfo
+	}
+	if err := vm.vcVM.Properties(vmCtx, vm.vcVM.Reference(), []string{"runtime"}, &o); err != nil {
+		return fmt.Errorf("fetching runtime state failed: %w", err)
+	}
+
+	if o.Runtime.PowerState == vimTypes.VirtualMachinePowerStatePoweredOn {
+		t, err := vm.vcVM.PowerOff(vmCtx)
+		if err != nil {
+			return fmt.Errorf("power off task failed: %w", err)
+		}
+		if err := t.Wait(vmCtx); err != nil {
+			return fmt.Errorf("power off of VM %q failed: %w", vm.vcVM.Name(), err)
+		}
+	}
+
+	t, err := vm.vcVM.Destroy(vmCtx)
+	if err != nil {
+		return fmt.Errorf("destroy task failed: %w", err)
+	}
+	if err := t.Wait(vmCtx); err != nil {
+		return fmt.Errorf("destroy of VM %q failed: %w", vm.vcVM.Name(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+// SnapshotTreeNode is a flattened view of a vimTypes.VirtualMachineSnapshotTree
+// node, with just the fields callers outside this package need.
+type SnapshotTreeNode struct {
+	MoRef       string
+	Description string
+	Children    []SnapshotTreeNode
+}
+
+// CreateSnapshot takes a new snapshot of the VM and returns its moref.
+func (vm *VirtualMachine) CreateSnapshot(
+	vmCtx context.VirtualMachineContext,
+	name, description string,
+	memory, quiesce bool) (string, error) {
+
+	t, err := vm.vcVM.CreateSnapshot(vmCtx, name, description, memory, quiesce)
+	if err != nil {
+		return "", fmt.Errorf("create snapshot task failed: %w", err)
+	}
+
+	taskInfo, err := t.WaitForResult(vmCtx)
+	if err != nil {
+		return "", taskFaultError("create", name, err)
+	}
+
+	ref, ok := taskInfo.Result.(vimTypes.ManagedObjectReference)
+	if !ok {
+		return "", fmt.Errorf("create snapshot task for %q did not return a moref", name)
+	}
+
+	return ref.Value, nil
+}
+
+// DeleteSnapshot removes the snapshot identified by moRef, re-parenting its
+// children onto its parent. moRef is accepted as the snapshot name, per
+// object.VirtualMachine.FindSnapshot's doc comment that a moref value works
+// equally well.
+func (vm *VirtualMachine) DeleteSnapshot(vmCtx context.VirtualMachineContext, moRef string) error {
+	t, err := vm.vcVM.RemoveSnapshot(vmCtx, moRef, false, nil)
+	if err != nil {
+		return fmt.Errorf("remove snapshot task failed: %w", err)
+	}
+
+	if err := t.Wait(vmCtx); err != nil {
+		return taskFaultError("delete", moRef, err)
+	}
+
+	return nil
+}
+
+// RevertSnapshot reverts the VM's disks, and runtime state if the snapshot
+// included memory, to the point captured by moRef.
+func (vm *VirtualMachine) RevertSnapshot(vmCtx context.VirtualMachineContext, moRef string) error {
+	t, err := vm.vcVM.RevertToSnapshot(vmCtx, moRef, false)
+	if err != nil {
+		return fmt.Errorf("revert snapshot task failed: %w", err)
+	}
+
+	if err := t.Wait(vmCtx); err != nil {
+		return taskFaultError("revert", moRef, err)
+	}
+
+	return nil
+}
+
+// GetSnapshotTree returns the VM's full snapshot tree.
+func (vm *VirtualMachine) GetSnapshotTree(vmCtx context.VirtualMachineContext) ([]SnapshotTreeNode, error) {
+	var o mo.VirtualMachine
+	if err := vm.vcVM.Properties(vmCtx, vm.vcVM.Reference(), []string{"snapshot"}, &o); err != nil {
+		return nil, fmt.Errorf("fetching snapshot property failed: %w", err)
+	}
+
+	if o.Snapshot == nil {
+		return nil, nil
+	}
+
+	return flattenSnapshotTree(o.Snapshot.RootSnapshotList), nil
+}
+
+func flattenSnapshotTree(nodes []vimTypes.VirtualMachineSnapshotTree) []SnapshotTreeNode {
+	out := make([]SnapshotTreeNode, 0, len(nodes))
+	for _, n := range nodes {
+		out = append(out, SnapshotTreeNode{
+			MoRef:       n.Snapshot.Value,
+			Description: n.Description,
+			Children:    flattenSnapshotTree(n.ChildSnapshotList),
+		})
+	}
+	return out
+}
+
+// taskFaultError translates a govmomi task.Error fault into a
+// vmprovider.SnapshotFaultError carrying a SnapshotFaultReason, so
+// reconcilers can map it onto a typed VirtualMachineSnapshot condition
+// instead of string-matching the message.
+func taskFaultError(op, moRef string, err error) error {
+	te, ok := err.(task.Error)
+	if !ok {
+		return &vmprovider.SnapshotFaultError{
+			Reason: vmprovider.SnapshotFaultReasonGeneric,
+			Err:    fmt.Errorf("%s snapshot %q failed: %w", op, moRef, err),
+		}
+	}
+
+	reason := vmprovider.SnapshotFaultReasonGeneric
+	switch te.Fault().(type) {
+	case *vimTypes.FileLocked, *vimTypes.ResourceInUse:
+		reason = vmprovider.SnapshotFaultReasonLocked
+	case *vimTypes.InvalidState:
+		reason = vmprovider.SnapshotFaultReasonInvalidState
+	case *vimTypes.NotSupported:
+		reason = vmprovider.SnapshotFaultReasonNotSupported
+	}
+
+	return &vmprovider.SnapshotFaultError{
+		Reason: reason,
+		Err:    fmt.Errorf("%s snapshot %q failed: %s", op, moRef, te.Fault()),
+	}
+}
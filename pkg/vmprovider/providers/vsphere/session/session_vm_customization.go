@@ -5,6 +5,9 @@ package session
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"text/template"
@@ -60,6 +63,108 @@ func GetLinuxPrepCustSpec(vmName string, updateArgs VMUpdateArgs) *vimTypes.Cust
 	}
 }
 
+// isWindowsGuest returns true if config identifies a Windows guest, either via the
+// GuestId reported by vSphere or the vmoperator.vmware.com/guest-os-family annotation,
+// which lets users force the Windows customization path for guest IDs we don't recognize.
+func isWindowsGuest(vmCtx context.VirtualMachineContext, config *vimTypes.VirtualMachineConfigInfo) bool {
+	if family := vmCtx.VM.Annotations[constants.GuestOSFamilyAnnotation]; family != "" {
+		return family == constants.GuestOSFamilyWindows
+	}
+	return strings.HasPrefix(strings.ToLower(config.GuestId), "win")
+}
+
+// isWindowsGuestAnnotated reports whether vm was explicitly marked as a Windows
+// guest via annotation. Unlike isWindowsGuest, it cannot consult config.GuestId,
+// so callers that only have the VM (e.g. cloud-init metadata rendering) fall back
+// to treating the guest as Linux when the annotation is absent.
+func isWindowsGuestAnnotated(vm *v1alpha1.VirtualMachine) bool {
+	return vm.Annotations[constants.GuestOSFamilyAnnotation] == constants.GuestOSFamilyWindows
+}
+
+// GetSysprepCustSpec returns the Windows analog of GetLinuxPrepCustSpec: a
+// CustomizationSysprep built from VMUpdateArgs, or a CustomizationSysprepText
+// wrapping a user-supplied unattend.xml when VMMetadata.Data["unattend"] is set.
+func GetSysprepCustSpec(vmName string, updateArgs VMUpdateArgs) *vimTypes.CustomizationSpec {
+	if unattend := updateArgs.VMMetadata.Data["unattend"]; unattend != "" {
+		return &vimTypes.CustomizationSpec{
+			Identity: &vimTypes.CustomizationSysprepText{
+				Value: unattend,
+			},
+			GlobalIPSettings: vimTypes.CustomizationGlobalIPSettings{
+				DnsServerList: updateArgs.DNSServers,
+			},
+			NicSettingMap: updateArgs.NetIfList.GetInterfaceCustomizations(),
+		}
+	}
+
+	data := updateArgs.VMMetadata.Data
+
+	computerName := truncateNetBIOSName(vmName)
+
+	sysprep := &vimTypes.CustomizationSysprep{
+		GuiUnattended: vimTypes.CustomizationGuiUnattended{
+			TimeZone:  defaultOrLookup(data["timezone"], 85), // 85 == UTC
+			AutoLogon: data["auto-logon"] == "true",
+			Password: &vimTypes.CustomizationPassword{
+				Value:     data["admin-password"],
+				PlainText: true,
+			},
+		},
+		UserData: vimTypes.CustomizationUserData{
+			ComputerName: &vimTypes.CustomizationFixedName{
+				Name: computerName,
+			},
+			FullName:  defaultOrString(data["full-name"], "VMware User"),
+			OrgName:   defaultOrString(data["org-name"], "VMware"),
+			ProductId: data["product-key"],
+		},
+		Identification: vimTypes.CustomizationIdentification{
+			JoinDomain:  data["join-domain"],
+			DomainAdmin: data["domain-admin"],
+			DomainAdminPassword: &vimTypes.CustomizationPassword{
+				Value:     data["domain-admin-password"],
+				PlainText: true,
+			},
+		},
+	}
+
+	return &vimTypes.CustomizationSpec{
+		Identity: sysprep,
+		GlobalIPSettings: vimTypes.CustomizationGlobalIPSettings{
+			DnsServerList: updateArgs.DNSServers,
+		},
+		NicSettingMap: updateArgs.NetIfList.GetInterfaceCustomizations(),
+	}
+}
+
+// truncateNetBIOSName truncates name to the 15-character limit Sysprep and
+// cloudbase-init both enforce on the NetBIOS computer name.
+func truncateNetBIOSName(name string) string {
+	const netBIOSNameMaxLen = 15
+	if len(name) > netBIOSNameMaxLen {
+		return name[:netBIOSNameMaxLen]
+	}
+	return name
+}
+
+func defaultOrString(val, def string) string {
+	if val == "" {
+		return def
+	}
+	return val
+}
+
+func defaultOrLookup(val string, def int32) int32 {
+	if val == "" {
+		return def
+	}
+	var tz int32
+	if _, err := fmt.Sscanf(val, "%d", &tz); err != nil {
+		return def
+	}
+	return tz
+}
+
 type CloudInitMetadata struct {
 	InstanceID    string          `yaml:"instance-id,omitempty"`
 	LocalHostname string          `yaml:"local-hostname,omitempty"`
@@ -72,10 +177,17 @@ func GetCloudInitMetadata(vm *v1alpha1.VirtualMachine,
 	netplan network.Netplan,
 	data map[string]string) (string, error) {
 
+	hostname := vm.Name
+	// cloudbase-init (the Windows analog of cloud-init) sets this as the NetBIOS
+	// computer name, which is limited to 15 characters.
+	if isWindowsGuestAnnotated(vm) {
+		hostname = truncateNetBIOSName(hostname)
+	}
+
 	metadataObj := &CloudInitMetadata{
 		InstanceID:    string(vm.UID),
-		LocalHostname: vm.Name,
-		Hostname:      vm.Name,
+		LocalHostname: hostname,
+		Hostname:      hostname,
 		Network:       netplan,
 		PublicKeys:    data["ssh-public-keys"],
 	}
@@ -200,6 +312,57 @@ func GetOvfEnvCustSpec(
 	return configSpec
 }
 
+// customizeNoCloud builds and uploads a NoCloud "cidata" ISO for the VM and
+// attaches it as a CD-ROM, for guest images that don't ship the VMware/OVF
+// cloud-init datasources (or that lack open-vm-tools for guestinfo).
+func customizeNoCloud(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine,
+	updateArgs VMUpdateArgs) error {
+
+	ethCards, err := resVM.GetNetworkDevices(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	netplan := updateArgs.NetIfList.GetNetplan(ethCards, updateArgs.DNSServers)
+
+	metadata, err := GetCloudInitMetadata(vmCtx.VM, netplan, updateArgs.VMMetadata.Data)
+	if err != nil {
+		return err
+	}
+
+	networkConfig, err := yaml.Marshal(netplan)
+	if err != nil {
+		return fmt.Errorf("marshalling network-config failed %v", err)
+	}
+
+	userdata := updateArgs.VMMetadata.Data["user-data"]
+	if userdata != "" {
+		plainText, err := util.TryToDecodeBase64Gzip([]byte(userdata))
+		if err != nil {
+			return fmt.Errorf("decoding NoCloud userdata failed %v", err)
+		}
+		userdata = plainText
+	}
+
+	iso, err := util.BuildNoCloudISO(metadata, userdata, string(networkConfig))
+	if err != nil {
+		return fmt.Errorf("building NoCloud ISO failed %v", err)
+	}
+
+	dsPath, err := resVM.UploadFileToDatastore(vmCtx, fmt.Sprintf("%s/cidata.iso", vmCtx.VM.Name), iso)
+	if err != nil {
+		return fmt.Errorf("uploading NoCloud ISO failed %v", err)
+	}
+
+	if err := res.AttachCDROMFromDatastore(vmCtx, resVM, dsPath); err != nil {
+		return fmt.Errorf("attaching NoCloud ISO failed %v", err)
+	}
+
+	return nil
+}
+
 func customizeCloudInit(
 	vmCtx context.VirtualMachineContext,
 	resVM *res.VirtualMachine,
@@ -237,6 +400,20 @@ func customizeCloudInit(
 	return configSpec, custSpec, nil
 }
 
+// getPrepCustSpec dispatches to GetLinuxPrepCustSpec or GetSysprepCustSpec based on
+// the guest family, so the OvfEnv/ExtraConfig/no-op transports also get proper
+// Windows customization instead of always falling back to Linux prep.
+func getPrepCustSpec(
+	vmCtx context.VirtualMachineContext,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs VMUpdateArgs) *vimTypes.CustomizationSpec {
+
+	if isWindowsGuest(vmCtx, config) {
+		return GetSysprepCustSpec(vmCtx.VM.Name, updateArgs)
+	}
+	return GetLinuxPrepCustSpec(vmCtx.VM.Name, updateArgs)
+}
+
 func (s *Session) customize(
 	vmCtx context.VirtualMachineContext,
 	resVM *res.VirtualMachine,
@@ -244,7 +421,9 @@ func (s *Session) customize(
 	updateArgs VMUpdateArgs) error {
 
 	if lib.IsVMServicePublicCloudBYOIFSSEnabled() {
-		TemplateVMMetadata(vmCtx, updateArgs)
+		if err := TemplateVMMetadata(vmCtx, updateArgs); err != nil {
+			return err
+		}
 	}
 
 	transport := updateArgs.VMMetadata.Transport
@@ -256,14 +435,16 @@ func (s *Session) customize(
 	switch transport {
 	case v1alpha1.VirtualMachineMetadataCloudInitTransport:
 		configSpec, custSpec, err = customizeCloudInit(vmCtx, resVM, config, updateArgs)
+	case constants.CloudInitNoCloudTransport:
+		err = customizeNoCloud(vmCtx, resVM, updateArgs)
 	case v1alpha1.VirtualMachineMetadataOvfEnvTransport:
 		configSpec = GetOvfEnvCustSpec(config, updateArgs)
-		custSpec = GetLinuxPrepCustSpec(vmCtx.VM.Name, updateArgs)
+		custSpec = getPrepCustSpec(vmCtx, config, updateArgs)
 	case v1alpha1.VirtualMachineMetadataExtraConfigTransport:
 		configSpec = GetExtraConfigCustSpec(config, updateArgs)
-		custSpec = GetLinuxPrepCustSpec(vmCtx.VM.Name, updateArgs)
+		custSpec = getPrepCustSpec(vmCtx, config, updateArgs)
 	default:
-		custSpec = GetLinuxPrepCustSpec(vmCtx.VM.Name, updateArgs)
+		custSpec = getPrepCustSpec(vmCtx, config, updateArgs)
 	}
 
 	if err != nil {
@@ -309,11 +490,12 @@ func (s *Session) customize(
 func NicInfoToDevicesStatus(vmCtx context.VirtualMachineContext, updateArgs VMUpdateArgs) []v1alpha1.NetworkDeviceStatus {
 	networkDevicesStatus := make([]v1alpha1.NetworkDeviceStatus, 0, len(updateArgs.NetIfList))
 
-	// TODO: Add MacAddress field when the generated mac is reflected into the updateArgs.NetIfList entries
 	for _, info := range updateArgs.NetIfList {
 		ipConfig := info.IPConfiguration
 		networkDevice := v1alpha1.NetworkDeviceStatus{
+			MACAddress:  info.MacAddress,
 			Gateway4:    ipConfig.Gateway,
+			Gateway6:    ipConfig.GatewayV6,
 			IPAddresses: []string{network.ToCidrNotation(ipConfig.IP, ipConfig.SubnetMask)},
 		}
 		networkDevicesStatus = append(networkDevicesStatus, networkDevice)
@@ -321,8 +503,74 @@ func NicInfoToDevicesStatus(vmCtx context.VirtualMachineContext, updateArgs VMUp
 	return networkDevicesStatus
 }
 
-// TemplateVMMetadata can convert templated expressions to dynamic configuration data.
-func TemplateVMMetadata(vmCtx context.VirtualMachineContext, updateArgs VMUpdateArgs) {
+// templateFuncMap are the extra functions available to VirtualMachineMetadata
+// templates, on top of text/template's defaults. They let users transform
+// values inline (e.g. base64-encoding a cert annotation) instead of having to
+// pre-encode everything in the VirtualMachineMetadata secret/configmap.
+func templateFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"base64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		"base64dec": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return "", fmt.Errorf("base64dec: %w", err)
+			}
+			return string(b), nil
+		},
+		"indent": func(spaces int, s string) string {
+			pad := strings.Repeat(" ", spaces)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"nindent": func(spaces int, s string) string {
+			pad := strings.Repeat(" ", spaces)
+			return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"toYaml": func(v interface{}) (string, error) {
+			b, err := yaml.Marshal(v)
+			if err != nil {
+				return "", fmt.Errorf("toYaml: %w", err)
+			}
+			return strings.TrimSuffix(string(b), "\n"), nil
+		},
+		"fromYaml": func(s string) (map[string]interface{}, error) {
+			out := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(s), &out); err != nil {
+				return nil, fmt.Errorf("fromYaml: %w", err)
+			}
+			return out, nil
+		},
+		"quote": func(s string) string {
+			return fmt.Sprintf("%q", s)
+		},
+		"sha256sum": func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		},
+		"default": func(def, val interface{}) interface{} {
+			if val == nil || val == "" {
+				return def
+			}
+			return val
+		},
+		"hasKey": func(m map[string]interface{}, key string) bool {
+			_, ok := m[key]
+			return ok
+		},
+		"lookup": func(m map[string]interface{}, key string) interface{} {
+			return m[key]
+		},
+	}
+}
+
+// TemplateVMMetadata converts templated expressions in the VM's metadata into
+// dynamic configuration data. By default, a template referencing a nonexistent
+// field is left un-rendered (and logged) so reconciliation can proceed; setting
+// the vmoperator.vmware.com/metadata-template-strict annotation to "true" makes
+// that case a hard reconcile failure instead, with a MetadataTemplateFailed
+// event identifying the offending key.
+func TemplateVMMetadata(vmCtx context.VirtualMachineContext, updateArgs VMUpdateArgs) error {
 
 	networkDevicesStatus := NicInfoToDevicesStatus(vmCtx, updateArgs)
 
@@ -340,6 +588,8 @@ func TemplateVMMetadata(vmCtx context.VirtualMachineContext, updateArgs VMUpdate
 		},
 	}
 
+	strict := vmCtx.VM.Annotations[constants.MetadataTemplateStrictAnnotation] == "true"
+
 	// skip parsing when encountering escape character('\{',"\}")
 	normalizeStr := func(str string) string {
 		if strings.Contains(str, "\\{") || strings.Contains(str, "\\}") {
@@ -349,25 +599,35 @@ func TemplateVMMetadata(vmCtx context.VirtualMachineContext, updateArgs VMUpdate
 		return str
 	}
 
-	renderTemplate := func(name, templateStr string) string {
-		templ, err := template.New(name).Parse(templateStr)
+	renderTemplate := func(name, templateStr string) (string, error) {
+		templ, err := template.New(name).Funcs(templateFuncMap()).Option("missingkey=error").Parse(templateStr)
 		if err != nil {
 			vmCtx.Logger.Error(err, "failed to parse template", "templateStr", templateStr)
-			// TODO: emit related events
-			return normalizeStr(templateStr)
+			if strict {
+				return "", fmt.Errorf("parsing template for key %q: %w", name, err)
+			}
+			return normalizeStr(templateStr), nil
 		}
 		var doc bytes.Buffer
-		err = templ.Execute(&doc, &templateData)
-		if err != nil {
+		if err := templ.Execute(&doc, &templateData); err != nil {
 			vmCtx.Logger.Error(err, "failed to execute template", "templateStr", templateStr)
-			// TODO: emit related events
-			return normalizeStr(templateStr)
+			if strict {
+				return "", fmt.Errorf("executing template for key %q: %w", name, err)
+			}
+			return normalizeStr(templateStr), nil
 		}
-		return normalizeStr(doc.String())
+		return normalizeStr(doc.String()), nil
 	}
 
 	data := updateArgs.VMMetadata.Data
 	for key, val := range data {
-		data[key] = renderTemplate(key, val)
+		rendered, err := renderTemplate(key, val)
+		if err != nil {
+			vmCtx.Recorder.EmitEvent(vmCtx.VM, "MetadataTemplateFailed", fmt.Sprintf("key %q: %v", key, err), true)
+			return err
+		}
+		data[key] = rendered
 	}
+
+	return nil
 }
@@ -0,0 +1,109 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+)
+
+func renderTemplateFunc(t *testing.T, tmpl string, data interface{}) string {
+	t.Helper()
+
+	templ, err := template.New("test").Funcs(templateFuncMap()).Parse(tmpl)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", tmpl, err)
+	}
+
+	var out bytes.Buffer
+	if err := templ.Execute(&out, data); err != nil {
+		t.Fatalf("Execute(%q) error = %v", tmpl, err)
+	}
+
+	return out.String()
+}
+
+func TestTemplateFuncMapBase64RoundTrip(t *testing.T) {
+	got := renderTemplateFunc(t, `{{ "hello" | base64enc | base64dec }}`, nil)
+	if got != "hello" {
+		t.Errorf("base64enc|base64dec round trip = %q, want %q", got, "hello")
+	}
+}
+
+func TestTemplateFuncMapIndentAndNindent(t *testing.T) {
+	if got, want := renderTemplateFunc(t, `{{ indent 2 "a\nb" }}`, nil), "  a\n  b"; got != want {
+		t.Errorf("indent = %q, want %q", got, want)
+	}
+	if got, want := renderTemplateFunc(t, `{{ nindent 2 "a\nb" }}`, nil), "\n  a\n  b"; got != want {
+		t.Errorf("nindent = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncMapToYamlFromYaml(t *testing.T) {
+	got := renderTemplateFunc(t, `{{ $m := fromYaml (toYaml .) }}{{ $m.key }}`, map[string]string{"key": "value"})
+	if got != "value" {
+		t.Errorf("toYaml|fromYaml round trip = %q, want %q", got, "value")
+	}
+}
+
+func TestTemplateFuncMapQuote(t *testing.T) {
+	if got, want := renderTemplateFunc(t, `{{ quote "a b" }}`, nil), `"a b"`; got != want {
+		t.Errorf("quote = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncMapSha256Sum(t *testing.T) {
+	got := renderTemplateFunc(t, `{{ sha256sum "hello" }}`, nil)
+	want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if got != want {
+		t.Errorf("sha256sum(%q) = %q, want %q", "hello", got, want)
+	}
+}
+
+func TestTemplateFuncMapDefault(t *testing.T) {
+	if got, want := renderTemplateFunc(t, `{{ default "fallback" "" }}`, nil), "fallback"; got != want {
+		t.Errorf("default with empty val = %q, want %q", got, want)
+	}
+	if got, want := renderTemplateFunc(t, `{{ default "fallback" "set" }}`, nil), "set"; got != want {
+		t.Errorf("default with non-empty val = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncMapHasKeyAndLookup(t *testing.T) {
+	data := map[string]interface{}{"m": map[string]interface{}{"present": "yes"}}
+	if got, want := renderTemplateFunc(t, `{{ hasKey .m "present" }}`, data), "true"; got != want {
+		t.Errorf("hasKey present = %q, want %q", got, want)
+	}
+	if got, want := renderTemplateFunc(t, `{{ hasKey .m "missing" }}`, data), "false"; got != want {
+		t.Errorf("hasKey missing = %q, want %q", got, want)
+	}
+	if got, want := renderTemplateFunc(t, `{{ lookup .m "present" }}`, data), "yes"; got != want {
+		t.Errorf("lookup present = %q, want %q", got, want)
+	}
+}
+
+func TestTruncateNetBIOSName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "short name is unchanged", in: "web-01", want: "web-01"},
+		{name: "exactly 15 characters is unchanged", in: "exactly-fifteen", want: "exactly-fifteen"},
+		{name: "longer than 15 characters is truncated", in: "a-very-long-vm-name", want: "a-very-long-vm-"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := truncateNetBIOSName(tc.in)
+			if got != tc.want {
+				t.Errorf("truncateNetBIOSName(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+			if len(got) > 15 {
+				t.Errorf("truncateNetBIOSName(%q) = %q, longer than the 15-character NetBIOS limit", tc.in, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+
+	topologyv1a1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+// GetAvailabilityZoneCapacity aggregates Summary.EffectiveCpu/EffectiveMemory
+// and running-VM reservations across every ClusterComputeResource backing az.
+func (vs *vSphereVMProvider) GetAvailabilityZoneCapacity(
+	ctx context.Context,
+	az *topologyv1a1.AvailabilityZone) (vmprovider.AvailabilityZoneCapacity, error) {
+
+	vimClient, err := vs.sessions.GetVimClient(ctx)
+	if err != nil {
+		return vmprovider.AvailabilityZoneCapacity{}, fmt.Errorf("getting vCenter client failed: %w", err)
+	}
+
+	var azCapacity vmprovider.AvailabilityZoneCapacity
+
+	for _, moID := range az.Spec.ClusterComputeResourceMoIDs {
+		cluster, err := getClusterCapacity(ctx, vimClient, moID)
+		if err != nil {
+			return vmprovider.AvailabilityZoneCapacity{}, fmt.Errorf("getting capacity for cluster %q: %w", moID, err)
+		}
+
+		azCapacity.Clusters = append(azCapacity.Clusters, cluster)
+		azCapacity.TotalCPU += cluster.TotalCPU
+		azCapacity.TotalMemory += cluster.TotalMemory
+		azCapacity.UsedCPU += cluster.UsedCPU
+		azCapacity.UsedMemory += cluster.UsedMemory
+		azCapacity.VMCount += cluster.VMCount
+	}
+
+	return azCapacity, nil
+}
+
+func getClusterCapacity(ctx context.Context, vimClient *vim25.Client, moID string) (vmprovider.ClusterCapacity, error) {
+	ref := vimTypes.ManagedObjectReference{Type: "ClusterComputeResource", Value: moID}
+	ccr := object.NewClusterComputeResource(vimClient, ref)
+
+	var o mo.ClusterComputeResource
+	if err := ccr.Properties(ctx, ccr.Reference(), []string{"summary", "resourcePool"}, &o); err != nil {
+		return vmprovider.ClusterCapacity{}, fmt.Errorf("fetching cluster summary failed: %w", err)
+	}
+
+	summary, ok := o.Summary.(*vimTypes.ClusterComputeResourceSummary)
+	if !ok {
+		return vmprovider.ClusterCapacity{}, fmt.Errorf("cluster %q returned no usable summary", moID)
+	}
+
+	usedCPU, usedMemory, vmCount, err := reservedByRunningVMs(ctx, vimClient, o.ResourcePool)
+	if err != nil {
+		return vmprovider.ClusterCapacity{}, fmt.Errorf("aggregating running VM reservations failed: %w", err)
+	}
+
+	return vmprovider.ClusterCapacity{
+		ClusterComputeResourceMoId: moID,
+		TotalCPU:                   int64(summary.EffectiveCpu),
+		TotalMemory:                summary.EffectiveMemory,
+		UsedCPU:                    usedCPU,
+		UsedMemory:                 usedMemory,
+		VMCount:                    vmCount,
+	}, nil
+}
+
+// reservedByRunningVMs sums the CPU/memory reservation of every powered-on VM
+// under pool's resource pool tree, the same pool vm-operator's own VMs land
+// in. vm-operator places each namespace's VMs in a namespace-scoped child
+// pool rather than pool itself, so the walk must recurse into every
+// descendant resource pool, not just read pool's own "vm" property.
+func reservedByRunningVMs(
+	ctx context.Context,
+	vimClient *vim25.Client,
+	pool *vimTypes.ManagedObjectReference) (usedCPU, usedMemory int64, vmCount int32, err error) {
+
+	if pool == nil {
+		return 0, 0, 0, nil
+	}
+
+	vmRefs, err := resourcePoolVMs(ctx, vimClient, *pool)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	if len(vmRefs) == 0 {
+		return 0, 0, 0, nil
+	}
+
+	var vmProps []mo.VirtualMachine
+	pc := property.DefaultCollector(vimClient)
+	if err := pc.Retrieve(ctx, vmRefs, []string{"runtime.powerState", "resourceConfig"}, &vmProps); err != nil {
+		return 0, 0, 0, fmt.Errorf("retrieving VM runtime info failed: %w", err)
+	}
+
+	for _, vm := range vmProps {
+		if vm.Runtime.PowerState != vimTypes.VirtualMachinePowerStatePoweredOn {
+			continue
+		}
+		vmCount++
+		if rc := vm.ResourceConfig; rc != nil {
+			if cpu := rc.CpuAllocation.Reservation; cpu != nil {
+				usedCPU += *cpu
+			}
+			if mem := rc.MemoryAllocation.Reservation; mem != nil {
+				usedMemory += *mem
+			}
+		}
+	}
+
+	return usedCPU, usedMemory, vmCount, nil
+}
+
+// resourcePoolVMs returns the morefs of every VM under pool, recursing into
+// its child resource pools.
+func resourcePoolVMs(ctx context.Context, vimClient *vim25.Client, pool vimTypes.ManagedObjectReference) ([]vimTypes.ManagedObjectReference, error) {
+	rp := object.NewResourcePool(vimClient, pool)
+
+	var o mo.ResourcePool
+	if err := rp.Properties(ctx, rp.Reference(), []string{"vm", "resourcePool"}, &o); err != nil {
+		return nil, fmt.Errorf("listing VMs in resource pool failed: %w", err)
+	}
+
+	vmRefs := append([]vimTypes.ManagedObjectReference{}, o.Vm...)
+
+	for _, child := range o.ResourcePool {
+		childVMs, err := resourcePoolVMs(ctx, vimClient, child)
+		if err != nil {
+			return nil, err
+		}
+		vmRefs = append(vmRefs, childVMs...)
+	}
+
+	return vmRefs, nil
+}
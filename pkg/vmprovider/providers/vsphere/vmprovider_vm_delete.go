@@ -0,0 +1,50 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
+
+	vmcontext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
+	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/resources"
+)
+
+// DeleteVirtualMachine powers off and destroys vm's underlying vSphere VM,
+// cleaning up any out-of-band resources (e.g. a NoCloud datasource ISO) that
+// wouldn't otherwise be removed along with it. It refuses to proceed while vm
+// still has snapshots, so a snapshot's lineage never outlives the VM it was
+// taken of.
+func (vs *vSphereVMProvider) DeleteVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine) error {
+	vmCtx := vmcontext.VirtualMachineContext{Context: ctx, Logger: log.WithValues("vmName", vm.NamespacedName()), VM: vm}
+
+	snaps, err := vs.ListVirtualMachineSnapshots(ctx, vm)
+	if err != nil {
+		return fmt.Errorf("checking for dependent snapshots of VM %q failed: %w", vm.NamespacedName(), err)
+	}
+	if len(snaps) > 0 {
+		return fmt.Errorf("VM %q has %d dependent snapshot(s): remove them before deleting the VM", vm.NamespacedName(), len(snaps))
+	}
+
+	resVM, err := vs.getResVM(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	if vm.Spec.VmMetadata != nil && vm.Spec.VmMetadata.Transport == constants.CloudInitNoCloudTransport {
+		dsPath := fmt.Sprintf("%s/cidata.iso", vm.Name)
+		if err := res.DetachAndDeleteNoCloudISO(vmCtx, resVM, dsPath); err != nil {
+			return fmt.Errorf("cleaning up NoCloud ISO for VM %q failed: %w", vm.NamespacedName(), err)
+		}
+	}
+
+	if err := resVM.Delete(vmCtx); err != nil {
+		return fmt.Errorf("deleting VM %q failed: %w", vm.NamespacedName(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/vmware-tanzu/vm-operator-api/api/v1alpha1"
+
+	snapshotv1a1 "github.com/vmware-tanzu/vm-operator/external/vm-operator-api/api/v1alpha1"
+	vmcontext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/resources"
+)
+
+var log = ctrl.Log.WithName("vsphere").WithName("snapshot")
+
+// CreateVirtualMachineSnapshot takes a new snapshot of the VM's current disk and
+// (optionally) memory state, and records the resulting moref on snap.Status.SnapshotID.
+func (vs *vSphereVMProvider) CreateVirtualMachineSnapshot(
+	ctx context.Context,
+	vm *v1alpha1.VirtualMachine,
+	snap *snapshotv1a1.VirtualMachineSnapshot) error {
+
+	vmCtx := vmcontext.VirtualMachineContext{Context: ctx, Logger: log.WithValues("vmName", vm.NamespacedName()), VM: vm}
+
+	resVM, err := vs.getResVM(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	snapshotID, err := resVM.CreateSnapshot(vmCtx, snap.Name, snap.Spec.Description, snap.Spec.IncludeMemory, snap.Spec.Quiesce)
+	if err != nil {
+		return fmt.Errorf("creating snapshot %q for VM %q failed: %w", snap.Name, vm.NamespacedName(), err)
+	}
+
+	snap.Status.SnapshotID = snapshotID
+	return nil
+}
+
+// DeleteVirtualMachineSnapshot removes a single snapshot from the VM's snapshot tree.
+// Children of snap are re-parented onto snap's parent by RemoveSnapshot_Task.
+func (vs *vSphereVMProvider) DeleteVirtualMachineSnapshot(
+	ctx context.Context,
+	vm *v1alpha1.VirtualMachine,
+	snap *snapshotv1a1.VirtualMachineSnapshot) error {
+
+	if snap.Status.SnapshotID == "" {
+		// Nothing was ever created on vSphere: nothing to do.
+		return nil
+	}
+
+	vmCtx := vmcontext.VirtualMachineContext{Context: ctx, Logger: log.WithValues("vmName", vm.NamespacedName()), VM: vm}
+
+	resVM, err := vs.getResVM(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	if err := resVM.DeleteSnapshot(vmCtx, snap.Status.SnapshotID); err != nil {
+		return fmt.Errorf("deleting snapshot %q for VM %q failed: %w", snap.Name, vm.NamespacedName(), err)
+	}
+
+	return nil
+}
+
+// RevertVirtualMachineSnapshot reverts the VM's disks (and, if the snapshot includes
+// memory, its runtime state) to the point captured by snap.
+func (vs *vSphereVMProvider) RevertVirtualMachineSnapshot(
+	ctx context.Context,
+	vm *v1alpha1.VirtualMachine,
+	snap *snapshotv1a1.VirtualMachineSnapshot) error {
+
+	if snap.Status.SnapshotID == "" {
+		return fmt.Errorf("snapshot %q has no recorded moref to revert to", snap.Name)
+	}
+
+	vmCtx := vmcontext.VirtualMachineContext{Context: ctx, Logger: log.WithValues("vmName", vm.NamespacedName()), VM: vm}
+
+	resVM, err := vs.getResVM(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	if err := resVM.RevertSnapshot(vmCtx, snap.Status.SnapshotID); err != nil {
+		return fmt.Errorf("reverting VM %q to snapshot %q failed: %w", vm.NamespacedName(), snap.Name, err)
+	}
+
+	return nil
+}
+
+// ListVirtualMachineSnapshots returns the VM's current vSphere snapshot tree,
+// flattened into the subset of VirtualMachineSnapshot fields we can derive from it.
+func (vs *vSphereVMProvider) ListVirtualMachineSnapshots(
+	ctx context.Context,
+	vm *v1alpha1.VirtualMachine) ([]snapshotv1a1.VirtualMachineSnapshot, error) {
+
+	vmCtx := vmcontext.VirtualMachineContext{Context: ctx, Logger: log.WithValues("vmName", vm.NamespacedName()), VM: vm}
+
+	resVM, err := vs.getResVM(vmCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := resVM.GetSnapshotTree(vmCtx)
+	if err != nil {
+		return nil, fmt.Errorf("listing snapshots for VM %q failed: %w", vm.NamespacedName(), err)
+	}
+
+	var snaps []snapshotv1a1.VirtualMachineSnapshot
+	var walk func(nodes []res.SnapshotTreeNode)
+	walk = func(nodes []res.SnapshotTreeNode) {
+		for _, n := range nodes {
+			snaps = append(snaps, snapshotv1a1.VirtualMachineSnapshot{
+				Spec: snapshotv1a1.VirtualMachineSnapshotSpec{
+					VirtualMachineName: vm.Name,
+					Description:        n.Description,
+				},
+				Status: snapshotv1a1.VirtualMachineSnapshotStatus{
+					SnapshotID: n.MoRef,
+				},
+			})
+			walk(n.Children)
+		}
+	}
+	walk(tree)
+
+	return snaps, nil
+}
+
+// getResVM resolves the resources.VirtualMachine backing vmCtx.VM through this
+// provider's namespace session cache, the same path CreateOrUpdateVirtualMachine uses.
+func (vs *vSphereVMProvider) getResVM(vmCtx vmcontext.VirtualMachineContext) (*res.VirtualMachine, error) {
+	ses, err := vs.sessions.GetSession(vmCtx, vmCtx.VM.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("getting session for namespace %q failed: %w", vmCtx.VM.Namespace, err)
+	}
+
+	resVM, err := ses.GetVirtualMachine(vmCtx)
+	if err != nil {
+		return nil, fmt.Errorf("getting VM %q failed: %w", vmCtx.VM.NamespacedName(), err)
+	}
+
+	return resVM, nil
+}